@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/config"
+	"webrtc-streamer/streamer"
+)
+
+// ---------- Configuration ----------
+
+var (
+	configPath  = flag.String("config", os.Getenv("CONFIG_FILE"), "설정 파일 (TOML, 환경 변수 CONFIG_FILE)")
+	printConfig = flag.Bool("print-config", false, "기본값/설정 파일/환경 변수/플래그를 합친 최종 설정을 출력하고 종료")
+
+	// 명령행에서 준 설정 - 환경 변수보다 우선하며 SIGHUP 때도 다시 적용한다
+	overrides []override
+)
+
+type override struct{ key, value string }
+
+func init() {
+	overrideFlag := func(name, key, usage string) {
+		flag.Func(name, usage+" ("+key+")", func(v string) error {
+			overrides = append(overrides, override{key, v})
+			return nil
+		})
+	}
+	overrideFlag("http-port", "http.port", "HTTP 포트")
+	overrideFlag("rtp-port", "rtp.video_port", "영상 RTP 수신 포트")
+	overrideFlag("rtp-audio-port", "rtp.audio_port", "음성 RTP 수신 포트")
+	overrideFlag("rtp-bind-ip", "rtp.bind_ip", "RTP 수신 주소")
+	flag.Func("set", "임의의 설정 키 지정, 예: -set dvr.window=10m (여러 번 사용 가능)", func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value")
+		}
+		overrides = append(overrides, override{strings.TrimSpace(key), value})
+		return nil
+	})
+}
+
+// 기본값 < 설정 파일 < 환경 변수 < 플래그 순서로 합치고 검증한다.
+// 검증만 실패하면 읽은 설정도 함께 반환한다 (--print-config).
+func loadConfig() (*config.Config, error) {
+	c := config.Default()
+	if *configPath != "" {
+		if err := c.LoadFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.ApplyEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		if err := c.Set(o.key, o.value); err != nil {
+			return nil, fmt.Errorf("flag: %w", err)
+		}
+	}
+	return &c, c.Validate()
+}
+
+func serverOptions(c *config.Config) streamer.Options {
+	opts := streamer.DefaultOptions()
+	opts.HTTPAddr = fmt.Sprintf(":%d", c.HTTP.Port)
+	if dir := c.HTTP.StaticDir; dir != "" {
+		if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+			log.Printf("Warning: http.static_dir %q is not a directory, web page disabled", dir)
+		} else {
+			opts.Static = os.DirFS(dir)
+		}
+	}
+
+	opts.RTPBindIP = c.RTP.BindIP
+	opts.RTPVideoPort = c.RTP.VideoPort
+	opts.RTPAudioPort = c.RTP.AudioPort
+
+	opts.ICEServers = iceServers(c.WebRTC.ICEServers)
+	opts.WebRTCBindIP = c.WebRTC.BindIP
+	opts.WebRTCUDPPort = c.WebRTC.UDPPort
+	opts.WebRTCTCPPort = c.WebRTC.TCPPort
+	opts.PublicIPs = c.WebRTC.PublicIPs
+
+	opts.PublishQueue = c.Events.PublishQueue
+	opts.ClientQueue = c.Events.ClientQueue
+	opts.History = c.Events.History
+	opts.Compression = c.Events.Compression
+	opts.StatusPushInterval = c.Events.StatusInterval
+
+	opts.PartialInterval = c.Subtitles.PartialInterval
+	opts.MaxSubtitleTextLength = c.Subtitles.MaxTextLength
+	opts.TranscriptMaxSessions = c.Subtitles.TranscriptSessions
+	opts.LiveVTTSegment = c.Subtitles.LiveVTTSegment
+	opts.LiveVTTWindow = c.Subtitles.LiveVTTWindow
+
+	opts.TranslateURL = c.Translate.URL
+	opts.TranslateAPIKey = c.Translate.APIKey
+	opts.TranslateTimeout = c.Translate.Timeout
+
+	opts.DVRWindow = c.DVR.Window
+	opts.DVRMaxBytes = c.DVR.MaxMB << 20
+
+	opts.RecordingDir = c.Recording.Dir
+	opts.Continuous = c.Recording.Continuous
+	opts.RecordSegment = c.Recording.Segment
+	opts.RecordRetention = c.Recording.Retention
+	opts.RecordMinFreeBytes = uint64(c.Recording.MinFreeMB) << 20
+
+	opts.HLSSegment = c.HLS.Segment
+	opts.HLSWindow = c.HLS.Window
+	opts.SnapshotJPEGCommand = c.Snapshot.JPEGCommand
+	return opts
+}
+
+func iceServers(list []config.ICEServer) []webrtc.ICEServer {
+	servers := make([]webrtc.ICEServer, 0, len(list))
+	for _, s := range list {
+		servers = append(servers, webrtc.ICEServer{URLs: s.URLs, Username: s.Username, Credential: s.Credential})
+	}
+	return servers
+}
+
+// SIGHUP - ICE 서버, 인증, 자막 설정은 바로 적용하고 나머지 변경은 재시작이 필요하다고 알린다.
+// 새 설정이 잘못되었으면 지금 설정을 그대로 유지한다.
+func reloadConfig(running *config.Config, srv *streamer.Server, auth *atomic.Pointer[config.Auth]) {
+	next, err := loadConfig()
+	if err != nil {
+		log.Printf("Config reload failed, keeping current settings:\n%s", formatErrors(err))
+		return
+	}
+	for _, key := range running.RestartRequired(next) {
+		log.Printf("Config reload: %s changed, restart required to apply", key)
+	}
+
+	srv.UpdateSettings(streamer.Settings{
+		ICEServers:            iceServers(next.WebRTC.ICEServers),
+		PartialInterval:       next.Subtitles.PartialInterval,
+		MaxSubtitleTextLength: next.Subtitles.MaxTextLength,
+	})
+	auth.Store(&next.Auth)
+	log.Printf("Config reloaded (ICE servers: %d, auth: %t, max subtitle length: %d)",
+		len(next.WebRTC.ICEServers), next.Auth.Token != "", next.Subtitles.MaxTextLength)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ---------- Auth ----------
+
+// Token이 설정되면 Public에 없는 모든 API 요청과 모든 DELETE 요청에
+// "Authorization: Bearer <token>" 헤더나 ?token= 쿼리를 요구한다.
+// Public 항목이 "/"로 끝나면 그 아래 경로 전체, 아니면 같은 경로만 연다.
+// 정적 파일(웹 페이지)은 이 검사를 거치지 않는다.
+type Auth struct {
+	Token  string
+	Public []string
+}
+
+// 웹 페이지와 시청자가 쓰는 엔드포인트
+var defaultPublicPaths = []string{
+	"/post", "/reset", "/ws", "/events", "/status", "/snapshot",
+	"/transcript", "/transcripts", "/live/",
+}
+
+var (
+	errMissingToken = errors.New("token required")
+	errInvalidToken = errors.New("invalid token")
+)
+
+func (a *Auth) Authorize(r *http.Request) error {
+	if !a.protects(r) {
+		return nil
+	}
+	return a.Require(r)
+}
+
+// 경로와 관계없이 토큰을 검사한다 (WebSocket/SSE의 운영용 토픽 구독 등)
+func (a *Auth) Require(r *http.Request) error {
+	if a.Token == "" {
+		return nil
+	}
+	token := r.URL.Query().Get("token")
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimPrefix(h, "Bearer ")
+	}
+	if token == "" {
+		return errMissingToken
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+		return errInvalidToken
+	}
+	return nil
+}
+
+func (a *Auth) protects(r *http.Request) bool {
+	if r.Method == http.MethodDelete {
+		return true
+	}
+	for _, p := range a.Public {
+		if r.URL.Path == p || strings.HasSuffix(p, "/") && strings.HasPrefix(r.URL.Path, p) {
+			return false
+		}
+	}
+	return true
+}
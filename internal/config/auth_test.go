@@ -0,0 +1,67 @@
+package config
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	a := &Auth{Token: "secret", Public: append([]string(nil), defaultPublicPaths...)}
+	tests := []struct {
+		method, target string
+		header         string
+		wantErr        error
+	}{
+		// 시청용 엔드포인트
+		{"POST", "/post", "", nil},
+		{"GET", "/ws", "", nil},
+		{"GET", "/events?topics=subtitle", "", nil},
+		{"GET", "/live/index.m3u8", "", nil},
+		{"GET", "/live/hls/seg-1.ts", "", nil},
+		{"GET", "/transcript?format=vtt", "", nil},
+		// 나머지는 토큰 필요
+		{"GET", "/ws/stats", "", errMissingToken},
+		{"GET", "/presence", "", errMissingToken},
+		{"POST", "/dvr/seek?offset=live", "", errMissingToken},
+		{"GET", "/dvr", "", errMissingToken},
+		{"GET", "/recordings", "", errMissingToken},
+		{"POST", "/subtitle", "", errMissingToken},
+		{"GET", "/postx", "", errMissingToken}, // 정확히 같은 경로만
+		{"GET", "/live", "", errMissingToken},  // "/live/" 아래만
+		{"DELETE", "/post", "", errMissingToken},
+		// 토큰 전달 방식
+		{"POST", "/subtitle", "Bearer secret", nil},
+		{"POST", "/subtitle?token=secret", "", nil},
+		{"POST", "/subtitle", "Bearer wrong", errInvalidToken},
+		{"POST", "/subtitle?token=secret", "Bearer wrong", errInvalidToken}, // 헤더가 우선
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		if err := a.Authorize(r); err != tt.wantErr {
+			t.Errorf("%s %s (%q) = %v, want %v", tt.method, tt.target, tt.header, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAuthorizeWithoutToken(t *testing.T) {
+	a := &Auth{}
+	for _, target := range []string{"/subtitle", "/presence", "/recordings"} {
+		if err := a.Authorize(httptest.NewRequest("DELETE", target, nil)); err != nil {
+			t.Errorf("DELETE %s without auth.token = %v, want nil", target, err)
+		}
+	}
+}
+
+// 운영용 토픽 구독은 경로와 관계없이 토큰을 본다
+func TestRequire(t *testing.T) {
+	a := &Auth{Token: "secret", Public: []string{"/ws"}}
+	if err := a.Require(httptest.NewRequest("GET", "/ws", nil)); err != errMissingToken {
+		t.Errorf("Require(/ws) = %v, want %v", err, errMissingToken)
+	}
+	if err := a.Require(httptest.NewRequest("GET", "/ws?token=secret", nil)); err != nil {
+		t.Errorf("Require(/ws?token=secret) = %v", err)
+	}
+}
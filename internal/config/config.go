@@ -0,0 +1,336 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------- Server configuration ----------
+
+// 설정 파일([section] key = value)과 환경 변수, 명령행 플래그가 모두 이 구조체를 채운다.
+// 우선순위: 기본값 < 설정 파일 < 환경 변수 < 플래그
+type Config struct {
+	HTTP struct {
+		Port            int
+		StaticDir       string // ""이면 웹 페이지를 제공하지 않음
+		ShutdownTimeout time.Duration
+	}
+	RTP struct {
+		BindIP    string
+		VideoPort int
+		AudioPort int
+	}
+	WebRTC struct {
+		BindIP     string
+		UDPPort    int // 0이면 세션마다 임시 포트
+		TCPPort    int // 0이면 ICE-TCP를 쓰지 않음
+		PublicIPs  []string
+		ICEServers []ICEServer
+	}
+	Events struct {
+		PublishQueue   int
+		ClientQueue    int
+		History        int
+		Compression    bool
+		StatusInterval time.Duration // 0이면 주기적 상태 전송 안 함
+	}
+	Subtitles struct {
+		PartialInterval    time.Duration
+		MaxTextLength      int
+		TranscriptSessions int
+		LiveVTTSegment     time.Duration
+		LiveVTTWindow      int
+	}
+	Translate struct {
+		URL     string // ""이면 번역하지 않음
+		APIKey  string
+		Timeout time.Duration
+	}
+	DVR struct {
+		Window time.Duration // 0이면 끔
+		MaxMB  int
+	}
+	Recording struct {
+		Dir        string
+		Continuous bool
+		Segment    time.Duration
+		Retention  time.Duration // 0이면 기간 제한 없음
+		MinFreeMB  int
+	}
+	HLS struct {
+		Segment time.Duration
+		Window  int
+	}
+	Snapshot struct {
+		JPEGCommand string
+	}
+	Auth Auth
+}
+
+type ICEServer struct {
+	URLs       []string
+	Username   string
+	Credential string
+}
+
+// 환경 변수가 하나도 없을 때와 같은 값
+func Default() Config {
+	var c Config
+	c.HTTP.Port = 8080
+	c.HTTP.StaticDir = "./static"
+	c.HTTP.ShutdownTimeout = 10 * time.Second
+
+	c.RTP.BindIP = "0.0.0.0"
+	c.RTP.VideoPort = 5004
+	c.RTP.AudioPort = 5006
+
+	c.WebRTC.ICEServers = []ICEServer{{URLs: []string{"stun:stun.l.google.com:19302"}}}
+
+	c.Events.PublishQueue = 1024
+	c.Events.ClientQueue = 256
+	c.Events.History = 500
+	c.Events.Compression = true
+	c.Events.StatusInterval = 10 * time.Second
+
+	c.Subtitles.PartialInterval = 250 * time.Millisecond
+	c.Subtitles.MaxTextLength = 500
+	c.Subtitles.TranscriptSessions = 20
+	c.Subtitles.LiveVTTSegment = 6 * time.Second
+	c.Subtitles.LiveVTTWindow = 10
+
+	c.Translate.Timeout = 3 * time.Second
+
+	c.DVR.MaxMB = 100
+
+	c.Recording.Dir = "./recordings"
+	c.Recording.Segment = 5 * time.Minute
+	c.Recording.Retention = 72 * time.Hour
+	c.Recording.MinFreeMB = 1024
+
+	c.HLS.Segment = 2 * time.Second
+	c.HLS.Window = 6
+
+	c.Auth.Public = append([]string(nil), defaultPublicPaths...)
+	return c
+}
+
+// 설정 항목 하나 - 파일 키, 환경 변수, 값 위치를 묶는다
+type field struct {
+	key    string        // section.name
+	env    string        // 환경 변수 (없으면 "")
+	unit   time.Duration // 기간 환경 변수가 정수일 때의 단위 (기존 *_SECONDS 등과 호환)
+	reload bool          // SIGHUP으로 바로 적용
+	secret bool          // --print-config에 값을 출력하지 않음
+	ptr    any           // *int, *string, *bool, *[]string, *time.Duration, *[]ICEServer
+}
+
+// 파일/출력 순서도 이 목록을 따른다
+func (c *Config) fields() []field {
+	return []field{
+		{key: "http.port", env: "HTTP_PORT", ptr: &c.HTTP.Port},
+		{key: "http.static_dir", env: "STATIC_DIR", ptr: &c.HTTP.StaticDir},
+		{key: "http.shutdown_timeout", env: "SHUTDOWN_TIMEOUT_SECONDS", unit: time.Second, ptr: &c.HTTP.ShutdownTimeout},
+
+		{key: "rtp.bind_ip", env: "RTP_BIND_IP", ptr: &c.RTP.BindIP},
+		{key: "rtp.video_port", env: "RTP_PORT", ptr: &c.RTP.VideoPort},
+		{key: "rtp.audio_port", env: "RTP_AUDIO_PORT", ptr: &c.RTP.AudioPort},
+
+		{key: "webrtc.bind_ip", env: "WEBRTC_BIND_IP", ptr: &c.WebRTC.BindIP},
+		{key: "webrtc.udp_port", env: "WEBRTC_UDP_PORT", ptr: &c.WebRTC.UDPPort},
+		{key: "webrtc.tcp_port", env: "WEBRTC_TCP_PORT", ptr: &c.WebRTC.TCPPort},
+		{key: "webrtc.public_ips", env: "WEBRTC_PUBLIC_IPS", ptr: &c.WebRTC.PublicIPs},
+		{key: "webrtc.ice_servers", env: "ICE_SERVERS", reload: true, ptr: &c.WebRTC.ICEServers},
+
+		{key: "events.publish_queue", env: "WS_PUBLISH_QUEUE", ptr: &c.Events.PublishQueue},
+		{key: "events.client_queue", env: "WS_CLIENT_QUEUE", ptr: &c.Events.ClientQueue},
+		{key: "events.history", env: "WS_HISTORY", ptr: &c.Events.History},
+		{key: "events.compression", env: "WS_COMPRESSION", ptr: &c.Events.Compression},
+		{key: "events.status_interval", env: "STATUS_PUSH_SECONDS", unit: time.Second, ptr: &c.Events.StatusInterval},
+
+		{key: "subtitles.partial_interval", env: "SUBTITLE_PARTIAL_INTERVAL_MS", unit: time.Millisecond, reload: true, ptr: &c.Subtitles.PartialInterval},
+		{key: "subtitles.max_text_length", env: "SUBTITLE_MAX_TEXT_LENGTH", reload: true, ptr: &c.Subtitles.MaxTextLength},
+		{key: "subtitles.transcript_sessions", env: "TRANSCRIPT_MAX_SESSIONS", ptr: &c.Subtitles.TranscriptSessions},
+		{key: "subtitles.live_vtt_segment", env: "LIVE_VTT_SEGMENT_SECONDS", unit: time.Second, ptr: &c.Subtitles.LiveVTTSegment},
+		{key: "subtitles.live_vtt_window", env: "LIVE_VTT_WINDOW", ptr: &c.Subtitles.LiveVTTWindow},
+
+		{key: "translate.url", env: "TRANSLATE_URL", ptr: &c.Translate.URL},
+		{key: "translate.api_key", env: "TRANSLATE_API_KEY", secret: true, ptr: &c.Translate.APIKey},
+		{key: "translate.timeout", env: "TRANSLATE_TIMEOUT_MS", unit: time.Millisecond, ptr: &c.Translate.Timeout},
+
+		{key: "dvr.window", env: "DVR_MINUTES", unit: time.Minute, ptr: &c.DVR.Window},
+		{key: "dvr.max_mb", env: "DVR_MAX_MB", ptr: &c.DVR.MaxMB},
+
+		{key: "recording.dir", env: "RECORDING_DIR", ptr: &c.Recording.Dir},
+		{key: "recording.continuous", env: "RECORD_CONTINUOUS", ptr: &c.Recording.Continuous},
+		{key: "recording.segment", env: "RECORD_SEGMENT_SECONDS", unit: time.Second, ptr: &c.Recording.Segment},
+		{key: "recording.retention", env: "RECORD_RETENTION_HOURS", unit: time.Hour, ptr: &c.Recording.Retention},
+		{key: "recording.min_free_mb", env: "RECORD_MIN_FREE_MB", ptr: &c.Recording.MinFreeMB},
+
+		{key: "hls.segment", env: "HLS_SEGMENT_SECONDS", unit: time.Second, ptr: &c.HLS.Segment},
+		{key: "hls.window", env: "HLS_WINDOW", ptr: &c.HLS.Window},
+
+		{key: "snapshot.jpeg_command", env: "SNAPSHOT_JPEG_COMMAND", ptr: &c.Snapshot.JPEGCommand},
+
+		{key: "auth.token", env: "AUTH_TOKEN", reload: true, secret: true, ptr: &c.Auth.Token},
+		{key: "auth.public", env: "AUTH_PUBLIC_PATHS", reload: true, ptr: &c.Auth.Public},
+	}
+}
+
+func (c *Config) lookup(key string) (field, bool) {
+	for _, f := range c.fields() {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// 빈 값은 설정하지 않은 것으로 본다 (기존 환경 변수 동작과 같음)
+func (c *Config) ApplyEnv(getenv func(string) string) error {
+	var errs []error
+	for _, f := range c.fields() {
+		if f.env == "" {
+			continue
+		}
+		v := getenv(f.env)
+		if v == "" {
+			continue
+		}
+		if err := f.setString(v, f.unit); err != nil {
+			errs = append(errs, fmt.Errorf("environment %s=%q: %w", f.env, v, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 명령행 --set key=value 와 개별 플래그. 기간은 "10s"처럼 단위를 붙인다.
+func (c *Config) Set(key, value string) error {
+	f, ok := c.lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown key %q%s", key, c.suggest(key))
+	}
+	if err := f.setString(value, 0); err != nil {
+		return fmt.Errorf("%s=%q: %w", key, value, err)
+	}
+	return nil
+}
+
+// 문자열 하나로 값을 설정 (환경 변수, 플래그). 목록은 쉼표로 나눈다.
+func (f field) setString(v string, unit time.Duration) error {
+	switch p := f.ptr.(type) {
+	case *string:
+		*p = v
+	case *int:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		*p = n
+	case *bool:
+		b, err := parseBool(v)
+		if err != nil {
+			return err
+		}
+		*p = b
+	case *time.Duration:
+		if unit > 0 {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				*p = time.Duration(n) * unit
+				return nil
+			}
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("expected a duration like \"10s\" or \"250ms\"")
+		}
+		*p = d
+	case *[]string:
+		*p = splitList(v)
+	case *[]ICEServer:
+		// URL마다 서버 하나 (인증 정보가 필요한 TURN은 설정 파일로)
+		var servers []ICEServer
+		for _, u := range splitList(v) {
+			servers = append(servers, ICEServer{URLs: []string{u}})
+		}
+		*p = servers
+	default:
+		panic("config: unsupported field type for " + f.key)
+	}
+	return nil
+}
+
+// 불리언 해석 (1, true, yes, on / 0, false, no, off)
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false")
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// 다시 읽은 설정과 비교해 재시작해야 적용되는 키 목록
+func (c *Config) RestartRequired(next *Config) []string {
+	var keys []string
+	nextFields := next.fields()
+	for i, f := range c.fields() {
+		if f.reload {
+			continue
+		}
+		a := reflect.ValueOf(f.ptr).Elem().Interface()
+		b := reflect.ValueOf(nextFields[i].ptr).Elem().Interface()
+		if !reflect.DeepEqual(a, b) {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
+
+// 오타로 보이는 키에 대한 제안 (" (did you mean ...?)")
+func (c *Config) suggest(key string) string {
+	best, bestDist := "", 3
+	for _, f := range c.fields() {
+		if d := editDistance(key, f.key); d < bestDist {
+			best, bestDist = f.key, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envOf(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+// 기본값 < 설정 파일 < 환경 변수 < 플래그
+func TestPrecedence(t *testing.T) {
+	const file = `
+[http]
+port = 9000
+shutdown_timeout = "20s"
+
+[rtp]
+video_port = 6000
+audio_port = 6002
+bind_ip = "127.0.0.1"
+`
+	c := Default()
+	if err := c.Load("test.toml", strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ApplyEnv(envOf(map[string]string{
+		"RTP_PORT":                 "7000",
+		"SHUTDOWN_TIMEOUT_SECONDS": "30",
+		"HTTP_PORT":                "", // 빈 값은 설정하지 않은 것
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("rtp.video_port", "8000"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key       string
+		got, want any
+	}{
+		{"http.port (파일)", c.HTTP.Port, 9000},
+		{"http.shutdown_timeout (환경 변수, 초 단위)", c.HTTP.ShutdownTimeout, 30 * time.Second},
+		{"rtp.video_port (플래그)", c.RTP.VideoPort, 8000},
+		{"rtp.audio_port (파일)", c.RTP.AudioPort, 6002},
+		{"rtp.bind_ip (파일)", c.RTP.BindIP, "127.0.0.1"},
+		{"hls.window (기본값)", c.HLS.Window, 6},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(c *Config) bool
+		wantErr string
+	}{
+		{"정수 기간은 변수 이름의 단위", map[string]string{"SUBTITLE_PARTIAL_INTERVAL_MS": "250"},
+			func(c *Config) bool { return c.Subtitles.PartialInterval == 250*time.Millisecond }, ""},
+		{"단위를 붙인 기간", map[string]string{"DVR_MINUTES": "90s"},
+			func(c *Config) bool { return c.DVR.Window == 90*time.Second }, ""},
+		{"불리언", map[string]string{"RECORD_CONTINUOUS": "yes", "WS_COMPRESSION": "off"},
+			func(c *Config) bool { return c.Recording.Continuous && !c.Events.Compression }, ""},
+		{"쉼표 목록", map[string]string{"AUTH_PUBLIC_PATHS": "/a, /b/,,"},
+			func(c *Config) bool { return slices.Equal(c.Auth.Public, []string{"/a", "/b/"}) }, ""},
+		{"ICE 서버 URL 목록", map[string]string{"ICE_SERVERS": "stun:a:3478,stun:b:3478"},
+			func(c *Config) bool {
+				return len(c.WebRTC.ICEServers) == 2 && c.WebRTC.ICEServers[1].URLs[0] == "stun:b:3478"
+			}, ""},
+		{"잘못된 정수", map[string]string{"HTTP_PORT": "80a"}, nil, "environment HTTP_PORT=\"80a\": expected an integer"},
+		{"잘못된 기간", map[string]string{"HLS_SEGMENT_SECONDS": "soon"}, nil, "HLS_SEGMENT_SECONDS"},
+		{"잘못된 불리언", map[string]string{"RECORD_CONTINUOUS": "maybe"}, nil, "expected true or false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			err := c.ApplyEnv(envOf(tt.env))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(&c) {
+				t.Errorf("ApplyEnv(%v) did not set the expected value", tt.env)
+			}
+		})
+	}
+}
+
+func TestSetUnknownKeySuggests(t *testing.T) {
+	c := Default()
+	err := c.Set("http.prot", "80")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "http.port"?`) {
+		t.Errorf("Set(http.prot) error = %v, want suggestion", err)
+	}
+	if err := c.Set("nothing.like.this", "1"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("Set(nothing.like.this) error = %v, want no suggestion", err)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	c := Default()
+	next := Default()
+	next.Auth.Token = "secret"                      // 바로 적용
+	next.Subtitles.PartialInterval = time.Second    // 바로 적용
+	next.HTTP.Port = 9090                           // 재시작 필요
+	next.WebRTC.PublicIPs = []string{"203.0.113.1"} // 재시작 필요
+	got := c.RestartRequired(&next)
+	if want := []string{"http.port", "webrtc.public_ips"}; !slices.Equal(got, want) {
+		t.Errorf("RestartRequired() = %v, want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // 오류에 들어 있어야 할 키 (비어 있으면 통과)
+	}{
+		{"기본값", func(c *Config) {}, nil},
+		{"포트 범위", func(c *Config) { c.HTTP.Port = 0; c.WebRTC.UDPPort = 70000 }, []string{"http.port", "webrtc.udp_port"}},
+		{"RTP 포트 중복", func(c *Config) { c.RTP.AudioPort = c.RTP.VideoPort }, []string{"rtp.audio_port"}},
+		{"WebRTC와 RTP 포트 충돌", func(c *Config) { c.WebRTC.UDPPort = c.RTP.VideoPort }, []string{"webrtc.udp_port"}},
+		{"IP 주소", func(c *Config) { c.RTP.BindIP = "localhost"; c.WebRTC.PublicIPs = []string{"1.2.3"} },
+			[]string{"rtp.bind_ip", "webrtc.public_ips"}},
+		{"TURN 인증 정보", func(c *Config) { c.WebRTC.ICEServers = []ICEServer{{URLs: []string{"turn:t:3478"}}} },
+			[]string{"webrtc.ice_servers[0]"}},
+		{"ICE URL 스킴", func(c *Config) { c.WebRTC.ICEServers = []ICEServer{{URLs: []string{"http://x"}}} },
+			[]string{"webrtc.ice_servers[0]"}},
+		{"번역 URL", func(c *Config) { c.Translate.URL = "ftp://x" }, []string{"translate.url"}},
+		{"DVR 용량", func(c *Config) { c.DVR.Window = time.Minute; c.DVR.MaxMB = 0 }, []string{"dvr.max_mb"}},
+		{"DVR이 꺼져 있으면 용량은 보지 않음", func(c *Config) { c.DVR.MaxMB = 0 }, nil},
+		{"녹화 폴더", func(c *Config) { c.Recording.Dir = "" }, []string{"recording.dir"}},
+		{"인증 경로", func(c *Config) { c.Auth.Public = []string{"live/"} }, []string{"auth.public"}},
+		{"없는 정적 파일 폴더는 경고만", func(c *Config) { c.HTTP.StaticDir = "/nonexistent" }, nil},
+		{"녹화 세그먼트 최소 길이", func(c *Config) { c.Recording.Segment = 5 * time.Second }, []string{"recording.segment"}},
+		{"여러 오류를 모두 보고", func(c *Config) { c.HLS.Window = 0; c.Events.ClientQueue = 0 },
+			[]string{"hls.window", "events.client_queue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.HTTP.StaticDir = t.TempDir()
+			tt.modify(&c)
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors for %v", tt.want)
+			}
+			for _, key := range tt.want {
+				if !strings.Contains(err.Error(), key+":") {
+					t.Errorf("Validate() = %v, want an error for %s", err, key)
+				}
+			}
+		})
+	}
+}
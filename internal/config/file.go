@@ -0,0 +1,599 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---------- Config file (TOML subset) ----------
+
+// 지원하는 문법:
+//
+//	# 주석
+//	[section]
+//	key = "문자열" | 'literal' | 123 | true | ["목록", ...]
+//	[[webrtc.ice_servers]]
+//
+// 문자열 이스케이프는 \" \\ \n \t \r \b \f \uXXXX \UXXXXXXXX 만 지원한다.
+// 모르는 키, 중복 키, 타입이 맞지 않는 값은 모두 줄 번호와 함께 오류로 보고한다.
+
+type tomlValue struct {
+	v    any // string, int64, bool, []any
+	line int
+}
+
+type tomlDoc struct {
+	values map[string]tomlValue              // "section.key"
+	tables map[string][]map[string]tomlValue // [[name]] 항목들 (키는 항목 안의 이름)
+	lines  map[string]int                    // [[name]] 첫 등장 줄
+}
+
+func (c *Config) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(path, f)
+}
+
+func (c *Config) Load(name string, r io.Reader) error {
+	doc, err := parseTOML(name, r)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s:%d: %s", name, line, fmt.Sprintf(format, args...)))
+	}
+
+	// 오류를 파일 순서대로 보고하도록 줄 번호 순으로 적용
+	keys := make([]string, 0, len(doc.values))
+	for key := range doc.values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return doc.values[keys[i]].line < doc.values[keys[j]].line })
+
+	for _, key := range keys {
+		val := doc.values[key]
+		f, ok := c.lookup(key)
+		if !ok {
+			fail(val.line, "unknown key %q%s", key, c.suggest(key))
+			continue
+		}
+		if err := f.setValue(val.v); err != nil {
+			fail(val.line, "%s: %v", key, err)
+		}
+	}
+
+	for name, entries := range doc.tables {
+		if name != "webrtc.ice_servers" {
+			fail(doc.lines[name], "unknown table [[%s]]", name)
+			continue
+		}
+		if _, ok := doc.values[name]; ok {
+			fail(doc.lines[name], "%s is set both inline and as [[%s]]", name, name)
+			continue
+		}
+		servers, tableErrs := iceServersFromTables(entries)
+		for _, e := range tableErrs {
+			fail(e.line, "%s: %s", name, e.msg)
+		}
+		c.WebRTC.ICEServers = servers
+	}
+	return errors.Join(errs...)
+}
+
+type lineError struct {
+	line int
+	msg  string
+}
+
+func iceServersFromTables(entries []map[string]tomlValue) ([]ICEServer, []lineError) {
+	var servers []ICEServer
+	var errs []lineError
+	for _, entry := range entries {
+		var s ICEServer
+		for key, val := range entry {
+			var err error
+			switch key {
+			case "urls":
+				// 문자열 하나도 허용
+				if str, ok := val.v.(string); ok {
+					s.URLs = []string{str}
+				} else {
+					err = setList(&s.URLs, val.v)
+				}
+			case "username":
+				err = setString(&s.Username, val.v)
+			case "credential":
+				err = setString(&s.Credential, val.v)
+			default:
+				err = fmt.Errorf("unknown key %q (expected urls, username, credential)", key)
+			}
+			if err != nil {
+				errs = append(errs, lineError{val.line, err.Error()})
+			}
+		}
+		servers = append(servers, s)
+	}
+	return servers, errs
+}
+
+// 파일에서 읽은 값을 항목 타입에 맞춰 설정
+func (f field) setValue(v any) error {
+	switch p := f.ptr.(type) {
+	case *string:
+		return setString(p, v)
+	case *int:
+		n, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %s", typeName(v))
+		}
+		*p = int(n)
+	case *bool:
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expected true or false, got %s", typeName(v))
+		}
+		*p = b
+	case *time.Duration:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a duration string like \"10s\", got %s", typeName(v))
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q (use units like \"250ms\", \"10s\", \"5m\")", s)
+		}
+		*p = d
+	case *[]string:
+		return setList(p, v)
+	case *[]ICEServer:
+		// 인라인으로는 빈 목록만 (서버는 [[webrtc.ice_servers]]로)
+		if list, ok := v.([]any); ok && len(list) == 0 {
+			*p = nil
+			return nil
+		}
+		return fmt.Errorf("use [[%s]] tables, or [] for none", f.key)
+	default:
+		panic("config: unsupported field type for " + f.key)
+	}
+	return nil
+}
+
+func setString(p *string, v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("expected a string, got %s", typeName(v))
+	}
+	*p = s
+	return nil
+}
+
+func setList(p *[]string, v any) error {
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("expected a list of strings, got %s", typeName(v))
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("expected a list of strings, found %s", typeName(item))
+		}
+		out = append(out, s)
+	}
+	*p = out
+	return nil
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case int64:
+		return "an integer"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "a list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func parseTOML(name string, r io.Reader) (*tomlDoc, error) {
+	doc := &tomlDoc{
+		values: make(map[string]tomlValue),
+		tables: make(map[string][]map[string]tomlValue),
+		lines:  make(map[string]int),
+	}
+	section := ""
+	var entry map[string]tomlValue // 현재 [[table]] 항목 (없으면 nil)
+
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		start := lineNo
+		line, err := stripComment(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, start, err)
+		}
+		// 여러 줄에 걸친 목록은 괄호가 닫힐 때까지 이어 붙인다
+		for bracketDepth(line) > 0 && sc.Scan() {
+			lineNo++
+			next, err := stripComment(sc.Text())
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", name, lineNo, err)
+			}
+			line += " " + next
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fail := func(format string, args ...any) (*tomlDoc, error) {
+			return nil, fmt.Errorf("%s:%d: %s", name, start, fmt.Sprintf(format, args...))
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return fail("malformed table header %q", line)
+			}
+			table := strings.TrimSpace(line[2 : len(line)-2])
+			if !validKey(table, true) {
+				return fail("invalid table name %q", table)
+			}
+			entry = make(map[string]tomlValue)
+			doc.tables[table] = append(doc.tables[table], entry)
+			if _, ok := doc.lines[table]; !ok {
+				doc.lines[table] = start
+			}
+			section = ""
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return fail("malformed section header %q", line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if !validKey(section, false) {
+				return fail("invalid section name %q", section)
+			}
+			entry = nil
+		default:
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return fail("expected key = value, got %q", line)
+			}
+			key := strings.TrimSpace(line[:eq])
+			if !validKey(key, false) {
+				return fail("invalid key %q (dotted keys are not supported, use [section])", key)
+			}
+			v, err := parseTOMLValue(strings.TrimSpace(line[eq+1:]))
+			if err != nil {
+				return fail("%s: %v", key, err)
+			}
+			val := tomlValue{v: v, line: start}
+
+			if entry != nil {
+				if _, dup := entry[key]; dup {
+					return fail("duplicate key %q", key)
+				}
+				entry[key] = val
+				continue
+			}
+			if section == "" {
+				return fail("key %q must be inside a [section]", key)
+			}
+			full := section + "." + key
+			if prev, dup := doc.values[full]; dup {
+				return fail("duplicate key %q (first set on line %d)", full, prev.line)
+			}
+			doc.values[full] = val
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return doc, nil
+}
+
+func validKey(s string, dotted bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || (!dotted && part != s) {
+			return false
+		}
+		for _, r := range part {
+			if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 문자열 밖의 # 부터 줄 끝까지 지운다
+func stripComment(line string) (string, error) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#':
+			return line[:i], nil
+		}
+	}
+	if quote != 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	return line, nil
+}
+
+// 문자열 밖에서 열린 [ 의 수 (값 부분만 센다)
+func bracketDepth(line string) int {
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return 0
+	}
+	depth := 0
+	var quote byte
+	for i := eq + 1; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+func parseTOMLValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case s[0] == '"':
+		str, rest, err := parseBasicString(s)
+		if err != nil {
+			return nil, err
+		}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return nil, fmt.Errorf("unexpected %q after string", rest)
+		}
+		return str, nil
+	case s[0] == '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		if rest := strings.TrimSpace(s[end+2:]); rest != "" {
+			return nil, fmt.Errorf("unexpected %q after string", rest)
+		}
+		return s[1 : end+1], nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, fmt.Errorf("unterminated list")
+		}
+		var list []any
+		for _, item := range splitTopLevel(s[1 : len(s)-1]) {
+			if item = strings.TrimSpace(item); item == "" {
+				continue // 마지막 쉼표
+			}
+			v, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		if list == nil {
+			list = []any{}
+		}
+		return list, nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s (strings and durations must be quoted)", s)
+	}
+	return n, nil
+}
+
+func parseBasicString(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch ch {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'u', 'U':
+				// \uXXXX, \UXXXXXXXX
+				n := 4
+				if s[i] == 'U' {
+					n = 8
+				}
+				if i+n >= len(s) {
+					return "", "", fmt.Errorf("unterminated string")
+				}
+				r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(r)) {
+					return "", "", fmt.Errorf("invalid escape \\%s", s[i:i+1+n])
+				}
+				b.WriteRune(rune(r))
+				i += n
+			default:
+				return "", "", fmt.Errorf("unsupported escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// 문자열과 괄호 밖의 쉼표로 나눈다
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, s[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// --print-config 출력. 같은 형식으로 다시 읽을 수 있다 (비밀 값은 주석 처리).
+func (c *Config) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	section := ""
+	for _, f := range c.fields() {
+		sec, key, _ := strings.Cut(f.key, ".")
+		if sec != section {
+			if section != "" {
+				bw.WriteString("\n")
+			}
+			fmt.Fprintf(bw, "[%s]\n", sec)
+			section = sec
+		}
+		if servers, ok := f.ptr.(*[]ICEServer); ok {
+			writeICEServers(bw, f.key, *servers)
+			continue
+		}
+		value := formatValue(f.ptr)
+		if f.secret && value != `""` {
+			fmt.Fprintf(bw, "# %s = (set, not shown)\n", key)
+			continue
+		}
+		fmt.Fprintf(bw, "%s = %s\n", key, value)
+	}
+	return bw.Flush()
+}
+
+func writeICEServers(w io.Writer, key string, servers []ICEServer) {
+	if len(servers) == 0 {
+		_, name, _ := strings.Cut(key, ".")
+		fmt.Fprintf(w, "%s = []\n", name)
+		return
+	}
+	for _, s := range servers {
+		fmt.Fprintf(w, "\n[[%s]]\nurls = %s\n", key, formatValue(&s.URLs))
+		if s.Username != "" {
+			fmt.Fprintf(w, "username = %s\n", quoteString(s.Username))
+		}
+		if s.Credential != "" {
+			fmt.Fprintf(w, "# credential = (set, not shown)\n")
+		}
+	}
+}
+
+// TOML 기본 문자열 - strconv.Quote의 \x, \a 같은 이스케이프는 TOML에 없으므로 직접 만든다
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func formatValue(ptr any) string {
+	switch p := ptr.(type) {
+	case *string:
+		return quoteString(*p)
+	case *int:
+		return strconv.Itoa(*p)
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *time.Duration:
+		return strconv.Quote(p.String())
+	case *[]string:
+		quoted := make([]string, len(*p))
+		for i, s := range *p {
+			quoted[i] = quoteString(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	panic(fmt.Sprintf("config: unsupported field type %T", ptr))
+}
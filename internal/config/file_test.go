@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	const file = `# 전체 주석
+[http]
+port = 9_000          # 밑줄 구분 정수
+static_dir = 'C:\web' # 리터럴 문자열은 이스케이프 없음
+shutdown_timeout = "1m30s"
+
+[webrtc]
+public_ips = [
+  "203.0.113.1", # 줄마다 주석
+  "203.0.113.2",
+]
+
+[[webrtc.ice_servers]]
+urls = "stun:stun.example.com:3478"
+
+[[webrtc.ice_servers]]
+urls = ["turn:turn.example.com:3478", "turns:turn.example.com:5349"]
+username = "user"
+credential = "p#ss\"word\\"
+
+[events]
+compression = false
+
+[subtitles]
+partial_interval = "250ms"
+
+[translate]
+url = "http://localhost:9000/translate#fragment"
+api_key = "caf\u00E9 \U0001F600\r"
+
+[auth]
+token = "tab\there"
+public = []
+`
+	c := Default()
+	if err := c.Load("test.toml", strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key       string
+		got, want any
+	}{
+		{"http.port", c.HTTP.Port, 9000},
+		{"http.static_dir", c.HTTP.StaticDir, `C:\web`},
+		{"http.shutdown_timeout", c.HTTP.ShutdownTimeout, 90 * time.Second},
+		{"webrtc.public_ips", c.WebRTC.PublicIPs, []string{"203.0.113.1", "203.0.113.2"}},
+		{"webrtc.ice_servers", c.WebRTC.ICEServers, []ICEServer{
+			{URLs: []string{"stun:stun.example.com:3478"}},
+			{URLs: []string{"turn:turn.example.com:3478", "turns:turn.example.com:5349"}, Username: "user", Credential: `p#ss"word\`},
+		}},
+		{"events.compression", c.Events.Compression, false},
+		{"subtitles.partial_interval", c.Subtitles.PartialInterval, 250 * time.Millisecond},
+		{"translate.url", c.Translate.URL, "http://localhost:9000/translate#fragment"},
+		{"translate.api_key", c.Translate.APIKey, "café 😀\r"},
+		{"auth.token", c.Auth.Token, "tab\there"},
+		{"auth.public", c.Auth.Public, []string{}},
+		{"rtp.video_port (기본값)", c.RTP.VideoPort, 5004},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.key, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string // 오류 메시지에 순서대로 들어 있어야 할 줄
+	}{
+		{"오타 제안", "[http]\nprot = 80\n",
+			[]string{`test.toml:2: unknown key "http.prot" (did you mean "http.port"?)`}},
+		{"섹션 이름 오타", "\n[htp]\nport = 80\n",
+			[]string{`test.toml:3: unknown key "htp.port" (did you mean "http.port"?)`}},
+		{"비슷한 키가 없으면 제안 없음", "[http]\ncompletely_unrelated = 1\n",
+			[]string{`test.toml:2: unknown key "http.completely_unrelated"` + "\n"}},
+		{"타입 불일치", "[http]\nport = \"80\"\n",
+			[]string{`test.toml:2: http.port: expected an integer, got a string`}},
+		{"기간은 문자열", "[http]\nshutdown_timeout = 10\n",
+			[]string{`test.toml:2: http.shutdown_timeout: expected a duration string like "10s", got an integer`}},
+		{"잘못된 기간", "[http]\nshutdown_timeout = \"10 seconds\"\n",
+			[]string{`test.toml:2: http.shutdown_timeout: invalid duration "10 seconds"`}},
+		{"불리언", "[events]\ncompression = \"yes\"\n",
+			[]string{`test.toml:2: events.compression: expected true or false, got a string`}},
+		{"목록 항목 타입", "[webrtc]\npublic_ips = [\"1.2.3.4\", 5]\n",
+			[]string{`test.toml:2: webrtc.public_ips: expected a list of strings, found an integer`}},
+		{"여러 오류를 줄 순서대로", "[http]\nport = \"x\"\n\n[rtp]\nvideo_prot = 1\nbind_ip = 1\n",
+			[]string{"test.toml:2: http.port", `test.toml:5: unknown key "rtp.video_prot" (did you mean "rtp.video_port"?)`, "test.toml:6: rtp.bind_ip"}},
+		{"따옴표 없는 문자열", "[rtp]\nbind_ip = 127.0.0.1\n",
+			[]string{`test.toml:2: bind_ip: invalid value 127.0.0.1 (strings and durations must be quoted)`}},
+		{"중복 키", "[http]\nport = 1\n\n[http]\nport = 2\n",
+			[]string{`test.toml:5: duplicate key "http.port" (first set on line 2)`}},
+		{"섹션 밖의 키", "port = 1\n",
+			[]string{`test.toml:1: key "port" must be inside a [section]`}},
+		{"점으로 이은 키", "[http]\nhttp.port = 1\n",
+			[]string{`test.toml:2: invalid key "http.port" (dotted keys are not supported, use [section])`}},
+		{"값 없음", "[http]\nport =\n",
+			[]string{`test.toml:2: port: missing value`}},
+		{"= 없음", "[http]\nport\n",
+			[]string{`test.toml:2: expected key = value, got "port"`}},
+		{"닫히지 않은 문자열", "[http]\nstatic_dir = \"./static\n",
+			[]string{`test.toml:2: unterminated string`}},
+		{"문자열 뒤 군더더기", "[http]\nstatic_dir = \"a\" b\n",
+			[]string{`test.toml:2: static_dir: unexpected "b" after string`}},
+		{"지원하지 않는 이스케이프", "[http]\nstatic_dir = \"a\\qb\"\n",
+			[]string{`test.toml:2: static_dir: unsupported escape \q`}},
+		{"잘못된 유니코드 이스케이프", "[http]\nstatic_dir = \"\\u12zz\"\n",
+			[]string{`test.toml:2: static_dir: invalid escape \u12zz`}},
+		{"닫히지 않은 여러 줄 목록", "[webrtc]\npublic_ips = [\n  \"1.2.3.4\",\n",
+			[]string{`test.toml:2: public_ips: unterminated list`}},
+		{"여러 줄 목록 다음 줄 번호", "[webrtc]\npublic_ips = [\n  \"1.2.3.4\",\n]\nbogus = 1\n",
+			[]string{`test.toml:5: unknown key "webrtc.bogus"`}},
+		{"잘못된 섹션 머리", "[http\nport = 1\n",
+			[]string{`test.toml:1: malformed section header "[http"`}},
+		{"잘못된 표 머리", "[[webrtc.ice_servers]\n",
+			[]string{`test.toml:1: malformed table header "[[webrtc.ice_servers]"`}},
+		{"모르는 표", "[[webrtc.turn]]\nurls = \"turn:x\"\n",
+			[]string{`test.toml:1: unknown table [[webrtc.turn]]`}},
+		{"ICE 서버 모르는 키", "[[webrtc.ice_servers]]\nurls = \"stun:x\"\npassword = \"p\"\n",
+			[]string{`test.toml:3: webrtc.ice_servers: unknown key "password" (expected urls, username, credential)`}},
+		{"ICE 서버 중복 키", "[[webrtc.ice_servers]]\nurls = \"stun:x\"\nurls = \"stun:y\"\n",
+			[]string{`test.toml:3: duplicate key "urls"`}},
+		{"ICE 서버 인라인과 표", "[webrtc]\nice_servers = []\n\n[[webrtc.ice_servers]]\nurls = \"stun:x\"\n",
+			[]string{`test.toml:4: webrtc.ice_servers is set both inline and as [[webrtc.ice_servers]]`}},
+		{"ICE 서버 인라인 목록", "[webrtc]\nice_servers = [\"stun:x\"]\n",
+			[]string{`test.toml:2: webrtc.ice_servers: use [[webrtc.ice_servers]] tables, or [] for none`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			err := c.Load("test.toml", strings.NewReader(tt.file))
+			if err == nil {
+				t.Fatalf("Load() = nil, want %q", tt.want)
+			}
+			msg := err.Error() + "\n"
+			pos := 0
+			for _, want := range tt.want {
+				i := strings.Index(msg[pos:], want)
+				if i < 0 {
+					t.Fatalf("Load() error =\n%s\nwant (in order) %q", err, tt.want)
+				}
+				pos += i + len(want)
+			}
+		})
+	}
+}
+
+// --print-config 출력은 다시 읽으면 같은 설정이 된다 (비밀 값 제외)
+func TestWriteRoundTrip(t *testing.T) {
+	c := Default()
+	c.HTTP.StaticDir = "C:\\web \"quoted\" é 한글"
+	c.Snapshot.JPEGCommand = "ffmpeg -f h264 -i - -frames:v 1 -f image2 -\r\x01"
+	c.WebRTC.PublicIPs = []string{"203.0.113.1", "2001:db8::1"}
+	c.WebRTC.ICEServers = []ICEServer{
+		{URLs: []string{"stun:a:3478"}},
+		{URLs: []string{"turn:b:3478"}, Username: "u\\ser", Credential: "hidden"},
+	}
+	c.DVR.Window = 90 * time.Second
+	c.Auth.Token = "hidden"
+	c.Auth.Public = []string{"/post", "/live/"}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "hidden") {
+		t.Fatalf("secret written:\n%s", buf.String())
+	}
+
+	got := Default()
+	if err := got.Load("print-config", &buf); err != nil {
+		t.Fatalf("Load(Write()) = %v\n%s", err, buf.String())
+	}
+	want := c
+	want.Auth.Token = ""
+	want.WebRTC.ICEServers = []ICEServer{c.WebRTC.ICEServers[0], {URLs: c.WebRTC.ICEServers[1].URLs, Username: "u\\ser"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"port", "port", 0},
+		{"prot", "port", 2},
+		{"htp.port", "http.port", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
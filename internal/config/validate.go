@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ---------- Validation ----------
+
+// 잘못된 항목을 모두 모아 키 이름과 함께 보고한다
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	port := func(key string, v int, allowZero bool) {
+		lo := 1
+		if allowZero {
+			lo = 0
+		}
+		if v < lo || v > 65535 {
+			fail(key, "must be between %d and 65535 (got %d)", lo, v)
+		}
+	}
+	atLeast := func(key string, v, minimum int) {
+		if v < minimum {
+			fail(key, "must be at least %d (got %d)", minimum, v)
+		}
+	}
+	duration := func(key string, d, minimum time.Duration) {
+		if d < minimum {
+			fail(key, "must be at least %s (got %s)", minimum, d)
+		}
+	}
+	ip := func(key, v string) {
+		if v != "" && net.ParseIP(v) == nil {
+			fail(key, "%q is not an IP address", v)
+		}
+	}
+
+	// http.static_dir는 실행 위치에 따라 없을 수 있으므로 시작할 때 경고만 한다
+	port("http.port", c.HTTP.Port, false)
+	duration("http.shutdown_timeout", c.HTTP.ShutdownTimeout, time.Second)
+
+	ip("rtp.bind_ip", c.RTP.BindIP)
+	port("rtp.video_port", c.RTP.VideoPort, false)
+	port("rtp.audio_port", c.RTP.AudioPort, false)
+	if c.RTP.VideoPort == c.RTP.AudioPort {
+		fail("rtp.audio_port", "must differ from rtp.video_port (both %d)", c.RTP.VideoPort)
+	}
+
+	ip("webrtc.bind_ip", c.WebRTC.BindIP)
+	port("webrtc.udp_port", c.WebRTC.UDPPort, true)
+	port("webrtc.tcp_port", c.WebRTC.TCPPort, true)
+	if p := c.WebRTC.UDPPort; p != 0 && (p == c.RTP.VideoPort || p == c.RTP.AudioPort) {
+		fail("webrtc.udp_port", "%d is already used for RTP ingest", p)
+	}
+	if c.WebRTC.TCPPort != 0 && c.WebRTC.TCPPort == c.HTTP.Port {
+		fail("webrtc.tcp_port", "%d is already used by http.port", c.WebRTC.TCPPort)
+	}
+	for _, v := range c.WebRTC.PublicIPs {
+		if net.ParseIP(v) == nil {
+			fail("webrtc.public_ips", "%q is not an IP address", v)
+		}
+	}
+	for i, s := range c.WebRTC.ICEServers {
+		key := fmt.Sprintf("webrtc.ice_servers[%d]", i)
+		if len(s.URLs) == 0 {
+			fail(key, "urls must not be empty")
+		}
+		for _, u := range s.URLs {
+			scheme, _, _ := strings.Cut(u, ":")
+			switch scheme {
+			case "stun", "stuns":
+			case "turn", "turns":
+				if s.Username == "" || s.Credential == "" {
+					fail(key, "TURN server %q needs username and credential", u)
+				}
+			default:
+				fail(key, "%q must start with stun:, stuns:, turn: or turns:", u)
+			}
+		}
+	}
+
+	atLeast("events.publish_queue", c.Events.PublishQueue, 1)
+	atLeast("events.client_queue", c.Events.ClientQueue, 1)
+	atLeast("events.history", c.Events.History, 0)
+	duration("events.status_interval", c.Events.StatusInterval, 0)
+
+	duration("subtitles.partial_interval", c.Subtitles.PartialInterval, 0)
+	atLeast("subtitles.max_text_length", c.Subtitles.MaxTextLength, 0)
+	atLeast("subtitles.transcript_sessions", c.Subtitles.TranscriptSessions, 1)
+	duration("subtitles.live_vtt_segment", c.Subtitles.LiveVTTSegment, time.Second)
+	atLeast("subtitles.live_vtt_window", c.Subtitles.LiveVTTWindow, 1)
+
+	if c.Translate.URL != "" {
+		u, err := url.Parse(c.Translate.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("translate.url", "%q is not an http(s) URL", c.Translate.URL)
+		}
+		duration("translate.timeout", c.Translate.Timeout, time.Millisecond)
+	}
+
+	duration("dvr.window", c.DVR.Window, 0)
+	if c.DVR.Window > 0 {
+		atLeast("dvr.max_mb", c.DVR.MaxMB, 1)
+	}
+
+	if c.Recording.Dir == "" {
+		fail("recording.dir", "must not be empty")
+	}
+	duration("recording.segment", c.Recording.Segment, 10*time.Second)
+	duration("recording.retention", c.Recording.Retention, 0)
+	atLeast("recording.min_free_mb", c.Recording.MinFreeMB, 0)
+
+	duration("hls.segment", c.HLS.Segment, time.Second)
+	atLeast("hls.window", c.HLS.Window, 1)
+
+	for _, p := range c.Auth.Public {
+		if !strings.HasPrefix(p, "/") {
+			fail("auth.public", "%q must start with /", p)
+		}
+	}
+	return errors.Join(errs...)
+}
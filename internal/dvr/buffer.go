@@ -0,0 +1,284 @@
+package dvr
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+)
+
+// ---------- Time-shift (DVR) ----------
+
+type Packet struct {
+	Pkt   *rtp.Packet
+	Video bool
+	At    time.Time // 수신 시각
+}
+
+// 키프레임으로 시작하는 패킷 묶음 (영상/오디오를 수신 순서대로)
+type gop struct {
+	start   time.Time
+	packets []Packet
+}
+
+// 수신한 RTP 패킷을 GOP 단위로 메모리에 보관해 라이브를 되감아 볼 수 있게 한다
+type Buffer struct {
+	window   time.Duration
+	maxBytes int
+	video    ingest.Stream
+	audio    ingest.Stream
+
+	mu         sync.Mutex
+	subscribed bool
+	gops       []*gop
+	firstID    int64 // gops[0]의 번호 (커서가 참조)
+	bytes      int
+	haveTS     bool
+	lastTS     uint32 // 마지막 영상 패킷의 RTP 타임스탬프
+	auStart    int    // 현재 GOP에서 진행 중인 프레임의 첫 패킷 위치
+	auKey      bool
+	notify     chan struct{} // 대기 중인 재생기가 있으면 새 패킷 때 닫고 교체
+	waiting    bool
+}
+
+func NewBuffer(window time.Duration, maxBytes int, video, audio ingest.Stream) *Buffer {
+	b := &Buffer{
+		window:   window,
+		maxBytes: maxBytes,
+		video:    video,
+		audio:    audio,
+		notify:   make(chan struct{}),
+	}
+	if err := b.subscribe(); err != nil {
+		log.Printf("Time-shift buffer not started: %v", err)
+	}
+	return b
+}
+
+// 수신이 아직 시작되지 않았으면 요청 때 다시 시도
+func (b *Buffer) subscribe() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribed {
+		return nil
+	}
+	unsubVideo, err := b.video.Subscribe(b.onVideo)
+	if err != nil {
+		return err
+	}
+	if _, err := b.audio.Subscribe(b.onAudio); err != nil {
+		unsubVideo()
+		return err
+	}
+	b.subscribed = true
+	return nil
+}
+
+func (b *Buffer) onVideo(pkt *rtp.Packet) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur := b.currentLocked()
+	newAU := !b.haveTS || pkt.Timestamp != b.lastTS
+	b.haveTS, b.lastTS = true, pkt.Timestamp
+	if newAU {
+		b.auKey = false
+		if cur != nil {
+			b.auStart = len(cur.packets)
+		}
+	}
+
+	if !b.auKey && media.RTPStartsKeyframe(pkt.Payload) {
+		b.auKey = true
+		// 키프레임이 든 프레임의 첫 패킷(AUD 등)부터 새 GOP로 옮긴다
+		g := &gop{start: now}
+		if cur != nil && !newAU && b.auStart < len(cur.packets) {
+			g.start = cur.packets[b.auStart].At
+			g.packets = append(g.packets, cur.packets[b.auStart:]...)
+			cur.packets = cur.packets[:b.auStart:b.auStart]
+		}
+		b.gops = append(b.gops, g)
+		b.auStart = 0
+		cur = g
+	}
+	if cur == nil {
+		return // 첫 키프레임 전
+	}
+
+	b.appendLocked(cur, Packet{Pkt: pkt, Video: true, At: now})
+}
+
+func (b *Buffer) onAudio(pkt *rtp.Packet) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cur := b.currentLocked(); cur != nil {
+		b.appendLocked(cur, Packet{Pkt: pkt, At: now})
+	}
+}
+
+func (b *Buffer) currentLocked() *gop {
+	if len(b.gops) == 0 {
+		return nil
+	}
+	return b.gops[len(b.gops)-1]
+}
+
+func (b *Buffer) appendLocked(g *gop, p Packet) {
+	g.packets = append(g.packets, p)
+	b.bytes += rtpPacketSize(p.Pkt)
+	b.trimLocked(p.At)
+	if b.waiting {
+		close(b.notify)
+		b.notify = make(chan struct{})
+		b.waiting = false
+	}
+}
+
+func rtpPacketSize(pkt *rtp.Packet) int {
+	return pkt.MarshalSize()
+}
+
+// 보관 기간을 넘었거나 용량을 넘으면 가장 오래된 GOP부터 버린다 (현재 GOP는 유지)
+func (b *Buffer) trimLocked(now time.Time) {
+	for len(b.gops) > 1 && (now.Sub(b.gops[1].start) > b.window || b.bytes > b.maxBytes) {
+		for _, p := range b.gops[0].packets {
+			b.bytes -= rtpPacketSize(p.Pkt)
+		}
+		b.gops[0] = nil
+		b.gops = b.gops[1:]
+		b.firstID++
+	}
+}
+
+type cursor struct {
+	gop int64
+	idx int
+}
+
+// t 시점을 포함하는 GOP의 시작 (버퍼보다 이르면 가장 오래된 GOP)
+func (b *Buffer) cursorAt(t time.Time) (cursor, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.gops) == 0 {
+		return cursor{}, false
+	}
+	i := 0
+	for i+1 < len(b.gops) && !b.gops[i+1].start.After(t) {
+		i++
+	}
+	return cursor{gop: b.firstID + int64(i)}, true
+}
+
+// 커서 위치의 패킷을 반환하고 커서를 옮긴다. first는 GOP의 첫 패킷인지, 아직 패킷이 없으면 알림 채널을 반환.
+// 커서가 보관 범위 밖으로 밀려났으면 가장 오래된 GOP로 옮기고 jumped를 true로 한다.
+func (b *Buffer) next(c *cursor) (p Packet, first bool, wait <-chan struct{}, jumped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c.gop < b.firstID {
+		c.gop, c.idx = b.firstID, 0
+		jumped = true
+	}
+	for {
+		i := int(c.gop - b.firstID)
+		if i >= len(b.gops) {
+			break
+		}
+		g := b.gops[i]
+		if c.idx < len(g.packets) {
+			p, first = g.packets[c.idx], c.idx == 0
+			c.idx++
+			return p, first, nil, jumped
+		}
+		if i == len(b.gops)-1 {
+			break
+		}
+		// 다음 GOP로 옮겨진 패킷을 이미 읽었으면 그만큼 건너뛴다
+		c.idx -= len(g.packets)
+		c.gop++
+	}
+	b.waiting = true
+	return Packet{}, false, b.notify, jumped
+}
+
+// t 이전에 시작한 GOP 중 가장 최근 것이 커서의 GOP보다 뒤면 그 처음으로 옮긴다
+func (b *Buffer) skip(c *cursor, t time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i := int(c.gop - b.firstID)
+	if i < 0 {
+		return false // next가 가장 오래된 GOP로 옮긴다
+	}
+	j := i
+	for j+1 < len(b.gops) && !b.gops[j+1].start.After(t) {
+		j++
+	}
+	if j == i {
+		return false
+	}
+	c.gop, c.idx = b.firstID+int64(j), 0
+	return true
+}
+
+// 커서가 가장 최근(라이브) GOP에 있는지
+func (b *Buffer) newest(c cursor) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return c.gop == b.firstID+int64(len(b.gops))-1
+}
+
+// 가장 오래된 GOP의 시작 시각
+func (b *Buffer) Oldest() (time.Time, bool) {
+	b.subscribe()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.gops) == 0 {
+		return time.Time{}, false
+	}
+	return b.gops[0].start, true
+}
+
+// from을 포함하는 GOP의 처음부터 to 이전에 받은 패킷까지 (클립 추출)
+func (b *Buffer) Range(from, to time.Time) []Packet {
+	c, ok := b.cursorAt(from)
+	if !ok {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var packets []Packet
+	for i := max(int(c.gop-b.firstID), 0); i < len(b.gops); i++ {
+		for _, p := range b.gops[i].packets {
+			if !p.At.Before(to) {
+				return packets
+			}
+			packets = append(packets, p)
+		}
+	}
+	return packets
+}
+
+type Status struct {
+	Enabled         bool    `json:"enabled"`
+	WindowSeconds   float64 `json:"window_seconds,omitempty"`
+	BufferedSeconds float64 `json:"buffered_seconds"`
+	Bytes           int     `json:"bytes"`
+	GOPs            int     `json:"gops"`
+}
+
+func (b *Buffer) Status() Status {
+	b.subscribe()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := Status{Enabled: true, WindowSeconds: b.window.Seconds(), Bytes: b.bytes, GOPs: len(b.gops)}
+	if len(b.gops) > 0 {
+		status.BufferedSeconds = media.RoundSeconds(time.Since(b.gops[0].start))
+	}
+	return status
+}
@@ -0,0 +1,133 @@
+package dvr
+
+import (
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/media/mediatest"
+)
+
+// GOP 크기(프레임 수)마다 키프레임을 보내는 가짜 수신과 버퍼
+func newTestBuffer(t *testing.T, window time.Duration, maxBytes int) (*Buffer, *mediatest.Stream, *mediatest.Stream) {
+	t.Helper()
+	video, audio := &mediatest.Stream{}, &mediatest.Stream{}
+	b := NewBuffer(window, maxBytes, video, audio)
+	if video.Subscribers() != 1 || audio.Subscribers() != 1 {
+		t.Fatal("NewBuffer did not subscribe to the ingest")
+	}
+	return b, video, audio
+}
+
+func sendFrames(video *mediatest.Stream, gen *mediatest.H264, n int) {
+	for i := 0; i < n; i++ {
+		pkts, _ := gen.Next()
+		video.Send(pkts...)
+	}
+}
+
+func TestBufferGOPs(t *testing.T) {
+	b, video, audio := newTestBuffer(t, time.Minute, 1<<20)
+
+	// 첫 키프레임 전의 패킷은 버린다
+	audio.Send((&mediatest.Opus{}).Next())
+	gen := &mediatest.H264{GOP: 3, TS: 1000}
+	sendFrames(video, gen, 7) // GOP 3개 (3 + 3 + 1 프레임)
+	audio.Send((&mediatest.Opus{}).Next())
+
+	status := b.Status()
+	if status.GOPs != 3 || !status.Enabled {
+		t.Fatalf("Status() = %+v, want 3 GOPs", status)
+	}
+	oldest, ok := b.Oldest()
+	if !ok {
+		t.Fatal("Oldest() = false")
+	}
+
+	// 가장 오래된 GOP부터 - 키프레임 프레임(STAP-A + IDR) 두 패킷으로 시작하고, 오디오는 마지막에 있다
+	packets := b.Range(oldest, time.Now().Add(time.Second))
+	if len(packets) != 3*2+4+1 {
+		t.Fatalf("Range() = %d packets, want 11", len(packets))
+	}
+	if !packets[0].Video || !media.RTPStartsKeyframe(packets[0].Pkt.Payload) || packets[0].Pkt.Timestamp != 1000 {
+		t.Errorf("Range() starts with %+v, want the first keyframe", packets[0].Pkt.Header)
+	}
+	if last := packets[len(packets)-1]; last.Video {
+		t.Error("audio after the last keyframe not kept")
+	}
+	if got := b.Range(oldest, oldest); len(got) != 0 {
+		t.Errorf("Range(t, t) = %d packets, want none", len(got))
+	}
+}
+
+// 용량을 넘으면 가장 오래된 GOP부터 버리고, 재생 중이던 커서는 가장 오래된 GOP로 옮겨진다
+func TestBufferTrimAndCursor(t *testing.T) {
+	b, video, _ := newTestBuffer(t, time.Minute, 1<<20)
+	gen := &mediatest.H264{GOP: 3}
+	sendFrames(video, gen, 3)
+	c, ok := b.cursorAt(time.Now())
+	if !ok {
+		t.Fatal("cursorAt() = false")
+	}
+	if _, first, wait, jumped := b.next(&c); !first || wait != nil || jumped {
+		t.Fatalf("next() first=%v wait=%v jumped=%v, want the first packet", first, wait, jumped)
+	}
+
+	b.mu.Lock()
+	b.maxBytes = b.bytes + 1 // 이후로는 GOP 하나만 남는다
+	b.mu.Unlock()
+	sendFrames(video, gen, 6)
+	if n := b.Status().GOPs; n != 1 {
+		t.Fatalf("%d GOPs after trim, want 1", n)
+	}
+	p, first, _, jumped := b.next(&c)
+	if !jumped || !first || p.Pkt.Timestamp != 6*mediatest.FrameTicks {
+		t.Errorf("next() after trim = ts %d first=%v jumped=%v, want a jump to the newest GOP", p.Pkt.Timestamp, first, jumped)
+	}
+	if !b.newest(c) {
+		t.Error("newest() = false on the only GOP")
+	}
+
+	// 끝까지 읽으면 새 패킷을 기다린다
+	for {
+		_, _, wait, _ := b.next(&c)
+		if wait != nil {
+			sendFrames(video, gen, 1)
+			select {
+			case <-wait:
+			case <-time.After(time.Second):
+				t.Fatal("waiting cursor not notified of a new packet")
+			}
+			break
+		}
+	}
+}
+
+func TestBufferSkip(t *testing.T) {
+	b, video, _ := newTestBuffer(t, time.Minute, 1<<20)
+	gen := &mediatest.H264{GOP: 2}
+	var starts []time.Time
+	for i := 0; i < 4; i++ {
+		starts = append(starts, time.Now())
+		sendFrames(video, gen, 2)
+		time.Sleep(2 * time.Millisecond)
+	}
+	c, _ := b.cursorAt(starts[0])
+
+	if b.skip(&c, starts[0]) {
+		t.Error("skip() moved the cursor to its own GOP")
+	}
+	if !b.skip(&c, starts[2].Add(time.Millisecond)) || c.gop != b.firstID+2 || c.idx != 0 {
+		t.Errorf("skip() cursor = %+v, want the start of the third GOP", c)
+	}
+	if b.skip(&c, starts[1]) {
+		t.Error("skip() moved the cursor backwards")
+	}
+	if b.newest(c) {
+		t.Error("newest() = true on the third of four GOPs")
+	}
+	b.skip(&c, time.Now())
+	if !b.newest(c) {
+		t.Error("newest() = false after skipping to now")
+	}
+}
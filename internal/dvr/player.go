@@ -0,0 +1,189 @@
+package dvr
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+)
+
+// ---------- Playback ----------
+
+// 따라잡는 속도. GOP 경계에서 이 배속의 기준 위치보다 앞서 시작한 GOP는 통째로 건너뛴다.
+const catchUpRate = 1.25
+
+// 버퍼에서 수신 당시 간격 그대로 패킷을 보내되, GOP 단위로 건너뛰며 catchUpRate배속으로 라이브를 따라잡는다.
+// 가장 최근 GOP(라이브와의 지연이 GOP 하나 미만)에 닿으면 onLive가 불린다.
+type Player struct {
+	buf          *Buffer
+	video, audio *Rewriter
+	onLive       func(*Player)
+	stop         chan struct{}
+	done         chan struct{}
+
+	mu       sync.Mutex
+	position time.Time // 마지막으로 보낸 패킷의 수신 시각
+}
+
+// onLive는 재생이 라이브에 따라잡았을 때 별도 고루틴에서 한 번 호출된다 (nil이면 무시)
+func (b *Buffer) Play(at time.Time, video, audio *Rewriter, onLive func(*Player)) (*Player, error) {
+	if err := b.subscribe(); err != nil {
+		return nil, err
+	}
+	c, ok := b.cursorAt(at)
+	if !ok {
+		return nil, errors.New("time-shift buffer is empty")
+	}
+	p := &Player{buf: b, video: video, audio: audio, onLive: onLive, stop: make(chan struct{}), done: make(chan struct{})}
+	go p.run(c)
+	return p, nil
+}
+
+func (p *Player) run(c cursor) {
+	defer close(p.done)
+
+	var wallStart, mediaStart time.Time  // 1배속 간격 기준 (건너뛰면 다시 잡는다)
+	var catchStart, catchMedia time.Time // 따라잡기 기준
+	for {
+		pkt, first, wait, jumped := p.buf.next(&c)
+		if jumped {
+			// 재생이 보관 범위 밖으로 밀려남 - 가장 오래된 GOP부터 다시 시작
+			p.discontinuity()
+			wallStart, catchStart = time.Time{}, time.Time{}
+		}
+		if wait != nil {
+			if !wallStart.IsZero() {
+				p.live()
+			}
+			select {
+			case <-wait:
+				continue
+			case <-p.stop:
+				return
+			}
+		}
+
+		if first && !catchStart.IsZero() {
+			target := catchMedia.Add(time.Duration(float64(time.Since(catchStart)) * catchUpRate))
+			if p.buf.skip(&c, target) {
+				p.discontinuity()
+				wallStart = time.Time{}
+				continue
+			}
+		}
+		if first && p.buf.newest(c) {
+			p.live()
+		}
+
+		if catchStart.IsZero() {
+			catchStart, catchMedia = time.Now(), pkt.At
+		}
+		if wallStart.IsZero() {
+			wallStart, mediaStart = time.Now(), pkt.At
+		}
+		if d := time.Until(wallStart.Add(pkt.At.Sub(mediaStart))); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-p.stop:
+				timer.Stop()
+				return
+			}
+		}
+
+		p.mu.Lock()
+		p.position = pkt.At
+		p.mu.Unlock()
+		if pkt.Video {
+			p.video.Write(pkt.Pkt)
+		} else {
+			p.audio.Write(pkt.Pkt)
+		}
+	}
+}
+
+func (p *Player) discontinuity() {
+	p.video.Discontinuity()
+	p.audio.Discontinuity()
+}
+
+// 라이브에 따라잡았음을 한 번 알린다. Stop을 기다리는 호출자와 엇갈리지 않도록 콜백은 따로 띄운다.
+func (p *Player) live() {
+	if p.onLive != nil {
+		go p.onLive(p)
+		p.onLive = nil
+	}
+}
+
+func (p *Player) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+// 라이브 대비 실제 지연
+func (p *Player) Delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.position.IsZero() {
+		return 0
+	}
+	return time.Since(p.position)
+}
+
+// 재생 위치가 바뀌어도 브라우저가 하나의 연속된 스트림으로 받도록
+// 시퀀스 번호와 타임스탬프를 이어 붙인다
+type Rewriter struct {
+	write      ingest.Sink
+	frameTicks uint32 // 끊긴 지점 사이에 둘 타임스탬프 간격
+	video      bool
+
+	mu       sync.Mutex
+	started  bool
+	resync   bool
+	needKey  bool
+	seqDelta uint16
+	tsDelta  uint32
+	lastSeq  uint16
+	lastTS   uint32
+}
+
+// frameTicks는 끊긴 지점 사이에 둘 타임스탬프 간격, video면 끊긴 뒤 키프레임부터 보낸다
+func NewRewriter(write ingest.Sink, frameTicks uint32, video bool) *Rewriter {
+	return &Rewriter{write: write, frameTicks: frameTicks, video: video}
+}
+
+func (w *Rewriter) Write(pkt *rtp.Packet) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 영상은 키프레임부터 이어 붙여야 디코딩이 깨지지 않는다
+	if w.needKey {
+		if !media.RTPStartsKeyframe(pkt.Payload) {
+			return
+		}
+		w.needKey = false
+	}
+	if w.resync && w.started {
+		w.seqDelta = w.lastSeq + 1 - pkt.SequenceNumber
+		w.tsDelta = w.lastTS + w.frameTicks - pkt.Timestamp
+	}
+	w.resync = false
+	w.started = true
+
+	out := *pkt
+	out.SequenceNumber += w.seqDelta
+	out.Timestamp += w.tsDelta
+	w.lastSeq, w.lastTS = out.SequenceNumber, out.Timestamp
+	w.write(&out)
+}
+
+func (w *Rewriter) Discontinuity() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resync = true
+	w.needKey = w.video
+}
@@ -0,0 +1,155 @@
+package dvr
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/media/mediatest"
+)
+
+// 보낸 패킷을 모으는 sink
+type collector struct {
+	mu   sync.Mutex
+	pkts []rtp.Packet
+}
+
+func (c *collector) write(pkt *rtp.Packet) {
+	c.mu.Lock()
+	c.pkts = append(c.pkts, *pkt)
+	c.mu.Unlock()
+}
+
+func (c *collector) packets() []rtp.Packet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]rtp.Packet(nil), c.pkts...)
+}
+
+// 30fps로 라이브 수신을 흉내 낸다 (GOP 3프레임 = 100ms)
+func feedLive(video, audio *mediatest.Stream, stop <-chan struct{}) {
+	vgen, agen := &mediatest.H264{GOP: 3}, &mediatest.Opus{}
+	ticker := time.NewTicker(time.Second / 30)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pkts, _ := vgen.Next()
+			video.Send(pkts...)
+			audio.Send(agen.Next())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 되감은 재생은 GOP를 건너뛰며 지연을 줄이고, 가장 최근 GOP에 닿으면 onLive를 부른다
+func TestPlayerCatchesUpToLive(t *testing.T) {
+	b, video, audio := newTestBuffer(t, time.Minute, 1<<20)
+	stop := make(chan struct{})
+	defer close(stop)
+	go feedLive(video, audio, stop)
+	time.Sleep(time.Second)
+
+	var vout, aout collector
+	live := make(chan time.Duration, 1)
+	p, err := b.Play(time.Now().Add(-600*time.Millisecond),
+		NewRewriter(vout.write, mediatest.FrameTicks, true), NewRewriter(aout.write, mediatest.AudioTicks, false),
+		func(p *Player) { live <- p.Delay() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	var delays []time.Duration
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	var atLive time.Duration
+wait:
+	for {
+		select {
+		case <-ticker.C:
+			if d := p.Delay(); d > 0 {
+				delays = append(delays, d)
+			}
+		case atLive = <-live:
+			break wait
+		case <-timeout:
+			t.Fatalf("onLive not called, delays %v", delays)
+		}
+	}
+
+	if len(delays) < 4 || delays[0] < 400*time.Millisecond {
+		t.Fatalf("delays %v, want playback to start about 600ms behind", delays)
+	}
+	if atLive >= 150*time.Millisecond {
+		t.Errorf("delay at onLive = %s, want under one GOP", atLive)
+	}
+	// 지연은 GOP를 건너뛸 때마다 줄어든다 (한 GOP 안에서는 그대로)
+	if last := delays[len(delays)-1]; last >= delays[0]-200*time.Millisecond {
+		t.Errorf("delays %v did not shrink", delays)
+	}
+	// 라이브 끝에서는 새 패킷을 받는 대로 보낸다 (지연은 프레임 간격 안팎)
+	atEdge := false
+	for i := 0; i < 100 && !atEdge; i++ {
+		time.Sleep(10 * time.Millisecond)
+		atEdge = p.Delay() < 50*time.Millisecond
+	}
+	if !atEdge {
+		t.Errorf("delay after onLive = %s, want to stay at the live edge", p.Delay())
+	}
+
+	// 건너뛴 지점에서도 브라우저가 받는 시퀀스 번호와 타임스탬프는 이어진다
+	pkts := vout.packets()
+	for i := 1; i < len(pkts); i++ {
+		if pkts[i].SequenceNumber != pkts[i-1].SequenceNumber+1 {
+			t.Fatalf("video sequence %d follows %d", pkts[i].SequenceNumber, pkts[i-1].SequenceNumber)
+		}
+		if step := pkts[i].Timestamp - pkts[i-1].Timestamp; step != 0 && step != mediatest.FrameTicks {
+			t.Fatalf("video timestamp step %d at packet %d", step, i)
+		}
+	}
+	if len(aout.packets()) == 0 {
+		t.Error("no audio played")
+	}
+}
+
+// 가장 최근 GOP에서 시작하면 바로 라이브이고, 버퍼에 있는 패킷을 모두 보낸다
+func TestPlayerStartsAtLiveEdge(t *testing.T) {
+	b, video, _ := newTestBuffer(t, time.Minute, 1<<20)
+	sendFrames(video, &mediatest.H264{GOP: 3}, 3)
+
+	var out collector
+	called := make(chan struct{}, 1)
+	p, err := b.Play(time.Now(), NewRewriter(out.write, mediatest.FrameTicks, true), NewRewriter(func(*rtp.Packet) {}, mediatest.AudioTicks, false),
+		func(*Player) { called <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("onLive not called in the newest GOP")
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(out.packets()) < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+	if n := len(out.packets()); n != 4 {
+		t.Errorf("played %d packets, want the whole GOP (4)", n)
+	}
+	select {
+	case <-called:
+		t.Error("onLive called twice")
+	default:
+	}
+
+	empty, _, _ := newTestBuffer(t, time.Minute, 1<<20)
+	if _, err := empty.Play(time.Now(), nil, nil, nil); err == nil {
+		t.Error("Play() on an empty buffer succeeded")
+	}
+}
@@ -0,0 +1,412 @@
+package hls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+)
+
+// ---------- HLS ----------
+
+const (
+	// 마지막 요청 후 이 시간이 지나면 RTP 구독을 멈춘다
+	hlsIdleTimeout = 30 * time.Second
+	// 재생 목록 요청이 첫 세그먼트를 기다리는 최대 시간
+	hlsWaitTimeout = 10 * time.Second
+	// 재생 목록에서 빠진 세그먼트도 늦게 받는 플레이어를 위해 잠시 보관
+	hlsExtraSegments = 2
+	// RTP 타임스탬프가 이보다 크게 튀면 송출기 재시작으로 보고 새 타임라인을 시작
+	hlsTimelineJump = 10 * time.Second
+)
+
+type hlsSegment struct {
+	seq           int64
+	initID        int
+	duration      time.Duration
+	start         time.Time
+	discontinuity bool
+	data          []byte
+}
+
+// UDP(WebRTC)를 쓸 수 없는 환경을 위한 HLS 출력.
+// 같은 H.264/Opus 수신을 조각 MP4 세그먼트로 나누며, 시청자가 요청할 때만 동작한다.
+// 타임라인은 라이브 WebVTT와 같은 epoch 기준이므로 /live/subtitles.m3u8과 바로 맞는다.
+type Output struct {
+	epoch        time.Time
+	target       time.Duration
+	window       int
+	video, audio ingest.Stream
+
+	mu         sync.Mutex
+	running    bool
+	unsubVideo func()
+	unsubAudio func()
+	lastAccess time.Time
+	changed    chan struct{} // 새 세그먼트가 생기면 닫히고 교체됨
+
+	depack        media.H264Depacketizer
+	channels      int
+	sps, pps      []byte
+	initID        int
+	inits         map[int][]byte
+	vbuf, abuf    media.TrackBuffer
+	waitKey       bool
+	discontinuity bool
+	segments      []*hlsSegment
+	nextSeq       int64
+	discDropped   int // 보관 목록에서 빠진 세그먼트의 discontinuity 수
+}
+
+func NewOutput(epoch time.Time, target time.Duration, window int, video, audio ingest.Stream) *Output {
+	if target < time.Second {
+		target = time.Second
+	}
+	if window < 3 {
+		window = 3
+	}
+	return &Output{
+		epoch:   epoch,
+		target:  target,
+		window:  window,
+		video:   video,
+		audio:   audio,
+		changed: make(chan struct{}),
+		inits:   make(map[int][]byte),
+	}
+}
+
+// 요청이 올 때마다 호출 - 멈춰 있으면 RTP 구독을 시작한다
+func (h *Output) Touch() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastAccess = time.Now()
+	if h.running {
+		return nil
+	}
+
+	var err error
+	if h.unsubVideo, err = h.video.Subscribe(h.onVideo); err != nil {
+		return err
+	}
+	if h.unsubAudio, err = h.audio.Subscribe(h.onAudio); err != nil {
+		h.unsubVideo()
+		return err
+	}
+	h.running = true
+	h.depack = media.H264Depacketizer{}
+	h.channels = 2
+	h.sps, h.pps = nil, nil
+	h.vbuf, h.abuf = media.TrackBuffer{}, media.TrackBuffer{}
+	go h.idleLoop()
+	log.Printf("HLS output started")
+	return nil
+}
+
+func (h *Output) idleLoop() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.mu.Lock()
+		if time.Since(h.lastAccess) < hlsIdleTimeout {
+			h.mu.Unlock()
+			continue
+		}
+		unsubs := []func(){h.unsubVideo, h.unsubAudio}
+		h.running = false
+		h.segments = nil
+		h.inits = make(map[int][]byte)
+		h.mu.Unlock()
+
+		for _, unsub := range unsubs {
+			unsub()
+		}
+		log.Printf("HLS output stopped (idle)")
+		return
+	}
+}
+
+// 첫 세그먼트가 만들어질 때까지 기다린다
+func (h *Output) WaitReady(ctx context.Context) bool {
+	timer := time.NewTimer(hlsWaitTimeout)
+	defer timer.Stop()
+	for {
+		h.mu.Lock()
+		ready, changed := len(h.segments) > 0, h.changed
+		h.mu.Unlock()
+		if ready {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// epoch 기준 경과 시간을 트랙 timescale 단위로 (장시간 실행에도 넘치지 않게 초 단위로 나눠 계산)
+func mediaTicks(d time.Duration, rate int64) int64 {
+	return int64(d/time.Second)*rate + int64(d%time.Second)*rate/int64(time.Second)
+}
+
+func mediaDuration(ticks, rate int64) time.Duration {
+	return time.Duration(ticks/rate)*time.Second + time.Duration(ticks%rate)*time.Second/time.Duration(rate)
+}
+
+func (h *Output) onVideo(pkt *rtp.Packet) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.running {
+		return
+	}
+
+	au := h.depack.Push(pkt)
+	if au == nil {
+		return
+	}
+	key := au.IsKey()
+	now := time.Now()
+
+	if key {
+		sps, pps := au.ParameterSets()
+		if sps != nil && pps != nil && (!bytes.Equal(sps, h.sps) || !bytes.Equal(pps, h.pps)) {
+			if !h.newInitLocked(sps, pps, now) {
+				return
+			}
+		}
+	}
+	if h.vbuf.Track == nil || (h.waitKey && !key) {
+		return
+	}
+	h.waitKey = false
+
+	data := au.AVCC()
+	if len(data) == 0 {
+		return
+	}
+	dts := h.vbuf.Base + h.vbuf.Clock.Extend(au.Timestamp)
+	if p := h.vbuf.Pending; p != nil {
+		if jump := mediaDuration(dts-p.DTS, media.VideoClockRate); jump > hlsTimelineJump || jump < -hlsTimelineJump {
+			log.Printf("HLS: RTP timestamp jumped by %s, starting a new timeline", jump)
+			h.restartTimelineLocked(now)
+			if !key {
+				h.waitKey = true
+				return
+			}
+			dts = h.vbuf.Base + h.vbuf.Clock.Extend(au.Timestamp)
+		} else if dts <= p.DTS {
+			dts = p.DTS + 1
+		}
+	}
+
+	h.vbuf.Push(media.TimedSample{DTS: dts, Data: data, Sync: key})
+	if key && h.vbuf.FragmentLength() >= h.target {
+		h.cutLocked()
+	}
+}
+
+func (h *Output) onAudio(pkt *rtp.Packet) {
+	if len(pkt.Payload) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.running {
+		return
+	}
+	if pkt.Payload[0]&0x04 != 0 {
+		h.channels = 2
+	} else {
+		h.channels = 1
+	}
+	if h.vbuf.Track == nil || h.waitKey {
+		return
+	}
+
+	if !h.abuf.Clock.Started {
+		h.abuf.Base = mediaTicks(time.Since(h.epoch), media.AudioClockRate)
+	}
+	dts := h.abuf.Base + h.abuf.Clock.Extend(pkt.Timestamp)
+	if p := h.abuf.Pending; p != nil && dts <= p.DTS {
+		return
+	}
+	h.abuf.Push(media.TimedSample{DTS: dts, Data: pkt.Payload, Sync: true})
+
+	// 영상이 끊긴 동안 오디오가 쌓이지 않게 버린다
+	if h.abuf.FragmentLength() > 3*h.target {
+		h.abuf.Take()
+	}
+}
+
+// SPS/PPS가 바뀌면 (해상도 변경, 송출기 재시작) 새 초기화 세그먼트로 시작
+func (h *Output) newInitLocked(sps, pps []byte, now time.Time) bool {
+	width, height, err := media.ParseSPSResolution(sps)
+	if err != nil {
+		log.Printf("HLS: %v", err)
+		return false
+	}
+
+	h.sps, h.pps = sps, pps
+	h.initID++
+	video := &media.MP4Track{ID: 1, Kind: media.MP4TrackVideo, Timescale: media.VideoClockRate,
+		Width: width, Height: height, SPS: sps, PPS: pps}
+	audio := &media.MP4Track{ID: 2, Kind: media.MP4TrackAudio, Timescale: media.AudioClockRate, Channels: h.channels}
+	h.inits[h.initID] = media.BuildMP4Init([]*media.MP4Track{video, audio})
+	h.vbuf.Track, h.abuf.Track = video, audio
+	h.restartTimelineLocked(now)
+	log.Printf("HLS: %dx%d H.264, %dch Opus", width, height, h.channels)
+	return true
+}
+
+// 만들던 세그먼트를 버리고 현재 시각부터 타임라인을 다시 시작
+func (h *Output) restartTimelineLocked(now time.Time) {
+	h.vbuf = media.TrackBuffer{Track: h.vbuf.Track, Base: mediaTicks(now.Sub(h.epoch), media.VideoClockRate)}
+	h.abuf = media.TrackBuffer{Track: h.abuf.Track}
+	h.discontinuity = len(h.segments) > 0
+}
+
+func (h *Output) cutLocked() {
+	vf, ok := h.vbuf.Take()
+	if !ok {
+		return
+	}
+	frags := []media.MP4TrackFragment{vf}
+	if af, ok := h.abuf.Take(); ok {
+		frags = append(frags, af)
+	}
+	var ticks int64
+	for _, s := range vf.Samples {
+		ticks += int64(s.Duration)
+	}
+
+	seg := &hlsSegment{
+		seq:           h.nextSeq,
+		initID:        h.initID,
+		duration:      mediaDuration(ticks, media.VideoClockRate),
+		start:         h.epoch.Add(mediaDuration(int64(vf.BaseTime), media.VideoClockRate)),
+		discontinuity: h.discontinuity,
+		data:          media.BuildMP4Fragment(uint32(h.nextSeq+1), frags),
+	}
+	h.nextSeq++
+	h.discontinuity = false
+
+	h.segments = append(h.segments, seg)
+	if excess := len(h.segments) - h.window - hlsExtraSegments; excess > 0 {
+		for _, old := range h.segments[:excess] {
+			if old.discontinuity {
+				h.discDropped++
+			}
+		}
+		h.segments = h.segments[excess:]
+	}
+
+	// 더 이상 참조되지 않는 초기화 세그먼트 정리
+	for id := range h.inits {
+		if id != h.initID && id < h.segments[0].initID {
+			delete(h.inits, id)
+		}
+	}
+
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+func (h *Output) MasterPlaylist() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bandwidth := 0
+	for _, seg := range h.segments {
+		if bps := int(float64(len(seg.data)*8) / seg.duration.Seconds()); bps > bandwidth {
+			bandwidth = bps
+		}
+	}
+	if bandwidth == 0 {
+		bandwidth = 2000000
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	b.WriteString(`#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Subtitles",DEFAULT=YES,AUTOSELECT=YES,URI="subtitles.m3u8"` + "\n")
+	fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,CODECS=\"avc1.%02x%02x%02x,opus\"",
+		bandwidth, h.sps[1], h.sps[2], h.sps[3])
+	if t := h.vbuf.Track; t != nil {
+		fmt.Fprintf(&b, ",RESOLUTION=%dx%d", t.Width, t.Height)
+	}
+	b.WriteString(",SUBTITLES=\"subs\"\nvideo.m3u8\n")
+	return b.String()
+}
+
+func (h *Output) MediaPlaylist() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	listed := h.segments
+	discSeq := h.discDropped
+	if len(listed) > h.window {
+		for _, seg := range listed[:len(listed)-h.window] {
+			if seg.discontinuity {
+				discSeq++
+			}
+		}
+		listed = listed[len(listed)-h.window:]
+	}
+
+	target := h.target
+	for _, seg := range listed {
+		target = max(target, seg.duration)
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(target.Seconds())))
+	if len(listed) > 0 {
+		fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", listed[0].seq)
+	}
+	fmt.Fprintf(&b, "#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", discSeq)
+
+	initID := 0
+	for _, seg := range listed {
+		if seg.discontinuity {
+			b.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		if seg.initID != initID {
+			initID = seg.initID
+			fmt.Fprintf(&b, "#EXT-X-MAP:URI=\"hls/init-%d.mp4\"\n", initID)
+		}
+		fmt.Fprintf(&b, "#EXT-X-PROGRAM-DATE-TIME:%s\n", seg.start.UTC().Format("2006-01-02T15:04:05.000Z"))
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\nhls/%d.m4s\n", seg.duration.Seconds(), seg.seq)
+	}
+	return b.String()
+}
+
+func (h *Output) Init(id int) ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	data, ok := h.inits[id]
+	return data, ok
+}
+
+func (h *Output) Segment(seq int64) ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, seg := range h.segments {
+		if seg.seq == seq {
+			return seg.data, true
+		}
+	}
+	return nil, false
+}
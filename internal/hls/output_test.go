@@ -0,0 +1,105 @@
+package hls
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/media/mediatest"
+)
+
+// 영상 n초 분량과 같은 길이의 오디오를 보낸다
+func sendSeconds(video, audio *mediatest.Stream, vgen *mediatest.H264, agen *mediatest.Opus, seconds int) {
+	for i := 0; i < seconds*30; i++ {
+		pkts, _ := vgen.Next()
+		video.Send(pkts...)
+		if i%3 == 0 {
+			audio.Send(agen.Next(), agen.Next())
+		}
+	}
+}
+
+func newTestOutput(t *testing.T) (*Output, *mediatest.Stream, *mediatest.Stream) {
+	t.Helper()
+	video, audio := &mediatest.Stream{}, &mediatest.Stream{}
+	h := NewOutput(time.Now(), time.Second, 3, video, audio)
+	if err := h.Touch(); err != nil {
+		t.Fatal(err)
+	}
+	return h, video, audio
+}
+
+func TestOutputSegments(t *testing.T) {
+	h, video, audio := newTestOutput(t)
+	if video.Subscribers() != 1 || audio.Subscribers() != 1 {
+		t.Fatal("Touch did not subscribe to the ingest")
+	}
+	vgen, agen := &mediatest.H264{}, &mediatest.Opus{}
+
+	// 키프레임마다 1초 세그먼트 - 6번째 키프레임에서 다섯 번째 세그먼트가 잘린다
+	sendSeconds(video, audio, vgen, agen, 5)
+	pkts, _ := vgen.Next()
+	video.Send(pkts...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !h.WaitReady(ctx) {
+		t.Fatal("WaitReady() = false")
+	}
+
+	// 재생 목록에는 window개, 늦은 요청을 위해 hlsExtraSegments개를 더 보관
+	playlist := h.MediaPlaylist()
+	for _, want := range []string{
+		"#EXT-X-TARGETDURATION:1\n",
+		"#EXT-X-MEDIA-SEQUENCE:2\n",
+		"#EXT-X-DISCONTINUITY-SEQUENCE:0\n",
+		"#EXT-X-MAP:URI=\"hls/init-1.mp4\"\n",
+		"#EXTINF:1.000,\nhls/2.m4s\n",
+		"hls/4.m4s\n",
+	} {
+		if !strings.Contains(playlist, want) {
+			t.Errorf("media playlist missing %q:\n%s", want, playlist)
+		}
+	}
+	if strings.Contains(playlist, "hls/1.m4s") || strings.Contains(playlist, "#EXT-X-DISCONTINUITY\n") {
+		t.Errorf("unexpected entries:\n%s", playlist)
+	}
+	for seq := int64(0); seq <= 4; seq++ {
+		if data, ok := h.Segment(seq); !ok || len(data) == 0 {
+			t.Errorf("Segment(%d) not kept", seq)
+		}
+	}
+	if init, ok := h.Init(1); !ok || len(init) == 0 {
+		t.Error("Init(1) missing")
+	}
+
+	master := h.MasterPlaylist()
+	for _, want := range []string{`CODECS="avc1.42c01e,opus"`, "RESOLUTION=320x240", `SUBTITLES="subs"`, "video.m3u8"} {
+		if !strings.Contains(master, want) {
+			t.Errorf("master playlist missing %q:\n%s", want, master)
+		}
+	}
+}
+
+// 송출기가 재시작해 타임스탬프가 튀면 새 타임라인을 discontinuity로 시작한다
+func TestOutputTimelineJump(t *testing.T) {
+	h, video, audio := newTestOutput(t)
+	vgen, agen := &mediatest.H264{}, &mediatest.Opus{}
+	sendSeconds(video, audio, vgen, agen, 2)
+
+	vgen.TS += uint32(time.Minute / time.Second * 90000)
+	sendSeconds(video, audio, vgen, agen, 2)
+	pkts, _ := vgen.Next()
+	video.Send(pkts...)
+
+	playlist := h.MediaPlaylist()
+	if n := strings.Count(playlist, "#EXT-X-DISCONTINUITY\n"); n != 1 {
+		t.Errorf("%d discontinuities, want 1:\n%s", n, playlist)
+	}
+	// 만들던 세그먼트는 버리고, 튄 뒤 첫 세그먼트 앞에 discontinuity가 온다
+	before, after, _ := strings.Cut(playlist, "#EXT-X-DISCONTINUITY\n")
+	if !strings.HasSuffix(before, "hls/0.m4s\n") || !strings.Contains(after, "hls/1.m4s\n") {
+		t.Errorf("discontinuity not between segments 0 and 1:\n%s", playlist)
+	}
+}
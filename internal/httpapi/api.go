@@ -0,0 +1,170 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/hls"
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/recording"
+	"webrtc-streamer/internal/session"
+	"webrtc-streamer/internal/snapshot"
+	"webrtc-streamer/internal/subtitles"
+	"webrtc-streamer/internal/sysstatus"
+)
+
+// ---------- HTTP API ----------
+
+type Config struct {
+	Hub           *hub.Hub
+	Sessions      *session.Manager
+	Transcripts   *subtitles.TranscriptStore
+	LiveSubtitles *subtitles.LiveTrack
+	Utterances    *subtitles.UtteranceTracker
+	Translations  *subtitles.Translations // nil이면 번역하지 않음
+	Recordings    *recording.Manager
+	HLS           *hls.Output
+	Keyframes     *snapshot.KeyframeCache
+	DVR           *dvr.Buffer // nil이면 시간 이동 버퍼 꺼짐
+
+	Status                func() any                  // GET /status 응답 (nil이면 시스템 상태)
+	OnSubtitle            func(subtitles.Data)        // 발행된 자막마다 호출 (nil 가능)
+	Authorize             func(r *http.Request) error // nil이 아닌 오류면 401로 거절
+	MaxSubtitleTextLength int
+	Compression           bool // 자막 생산자 WebSocket permessage-deflate 협상
+}
+
+// 스트리밍, 자막, 녹화 HTTP 엔드포인트. 의존성은 모두 Config로 받는다.
+type API struct {
+	hub           *hub.Hub
+	sessions      *session.Manager
+	transcripts   *subtitles.TranscriptStore
+	liveSubtitles *subtitles.LiveTrack
+	utterances    *subtitles.UtteranceTracker
+	translations  *subtitles.Translations
+	recordings    *recording.Manager
+	hls           *hls.Output
+	keyframes     *snapshot.KeyframeCache
+	dvr           *dvr.Buffer
+
+	status                func() any
+	onSubtitle            func(subtitles.Data)
+	authorize             func(r *http.Request) error
+	maxSubtitleTextLength atomic.Int64
+
+	producerUpgrader websocket.Upgrader
+	closing          atomic.Bool
+}
+
+func New(cfg Config) *API {
+	status := cfg.Status
+	if status == nil {
+		status = func() any { return sysstatus.Get() }
+	}
+	a := &API{
+		hub:           cfg.Hub,
+		sessions:      cfg.Sessions,
+		transcripts:   cfg.Transcripts,
+		liveSubtitles: cfg.LiveSubtitles,
+		utterances:    cfg.Utterances,
+		translations:  cfg.Translations,
+		recordings:    cfg.Recordings,
+		hls:           cfg.HLS,
+		keyframes:     cfg.Keyframes,
+		dvr:           cfg.DVR,
+		status:        status,
+		onSubtitle:    cfg.OnSubtitle,
+		authorize:     cfg.Authorize,
+		producerUpgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Allow connections from any origin
+			},
+			EnableCompression: cfg.Compression,
+		},
+	}
+	a.maxSubtitleTextLength.Store(int64(cfg.MaxSubtitleTextLength))
+	return a
+}
+
+// 자막 본문 최대 길이를 바꾼다 (설정 다시 읽기)
+func (a *API) SetMaxSubtitleTextLength(n int) {
+	a.maxSubtitleTextLength.Store(int64(n))
+}
+
+// 정적 파일("/")을 제외한 모든 엔드포인트를 mux에 등록한다
+func (a *API) Register(mux *http.ServeMux) {
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, a.requireAuth(h))
+	}
+
+	// WebSocket 엔드포인트
+	handle("/ws", a.rejectWhileClosing(a.hub.ServeWebSocket))
+	handle("/ws/stats", a.hub.ServeStats)
+	handle("/events", a.rejectWhileClosing(a.hub.ServeEvents))
+	handle("/presence", a.hub.Presence().ServeHTTP)
+
+	// HTTP 엔드포인트
+	handle("/post", a.handlePost)
+	handle("/reset", a.handleReset)
+	handle("/subtitle", a.handleSubtitle)
+	handle("/subtitle/stream", a.handleSubtitleStream)
+	handle("/subtitle/ws", a.handleSubtitleWebSocket)
+	handle("/status", a.handleStatus)
+	handle("/transcripts", a.handleTranscripts)
+	handle("/transcript", a.handleTranscript)
+	handle("/live/subtitles.m3u8", a.handleLiveSubtitlePlaylist)
+	handle("/live/subtitles/", a.handleLiveSubtitleSegment)
+	handle("/live/index.m3u8", a.handleHLSMaster)
+	handle("/live/video.m3u8", a.handleHLSMedia)
+	handle("/live/hls/", a.handleHLSSegment)
+	handle("/snapshot", a.handleSnapshot)
+	handle("/dvr", a.handleDVRStatus)
+	handle("/dvr/seek", a.handleDVRSeek)
+	handle("/recording", a.handleRecordingStatus)
+	handle("/recording/start", a.handleRecordingStart)
+	handle("/recording/stop", a.handleRecordingStop)
+	handle("/recording/continuous", a.handleContinuousRecordingStatus)
+	handle("/recordings", a.handleRecordings)
+	handle("/recordings/", a.handleRecordingItem)
+	handle("/clips", a.handleClips)
+}
+
+func (a *API) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	if a.authorize == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := a.authorize(r); err != nil {
+			log.Printf("Unauthorized %s %s: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// 종료가 시작되면 새 세션(/post, /ws, /events)을 받지 않는다
+func (a *API) BeginShutdown() {
+	a.closing.Store(true)
+}
+
+func (a *API) rejectClosing(w http.ResponseWriter) bool {
+	if !a.closing.Load() {
+		return false
+	}
+	w.Header().Set("Connection", "close")
+	http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+	return true
+}
+
+func (a *API) rejectWhileClosing(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.rejectClosing(w) {
+			next(w, r)
+		}
+	}
+}
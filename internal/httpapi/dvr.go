@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/session"
+)
+
+// ---------- Time-shift HTTP ----------
+
+// 초 단위 오프셋, "live" 또는 빈 값은 0
+func parseSeekOffset(v string) (time.Duration, error) {
+	if v == "" || v == "live" {
+		return 0, nil
+	}
+	sec, err := strconv.ParseFloat(v, 64)
+	if err != nil || sec < 0 {
+		return 0, errors.New("offset must be a non-negative number of seconds or \"live\"")
+	}
+	return time.Duration(sec * float64(time.Second)), nil
+}
+
+// GET /dvr - 시간 이동 버퍼와 현재 세션 상태
+func (a *API) handleDVRStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var status struct {
+		dvr.Status
+		Session *session.Status `json:"session,omitempty"`
+	}
+	if a.dvr != nil {
+		status.Status = a.dvr.Status()
+	}
+	if s := a.sessions.Current(); s != nil {
+		sessionStatus := s.Status()
+		status.Session = &sessionStatus
+	}
+	writeRecordingJSON(w, http.StatusOK, status)
+}
+
+// POST /dvr/seek?offset=<초|live> - 진행 중인 WebRTC 세션의 재생 위치 변경
+func (a *API) handleDVRSeek(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	offset, err := parseSeekOffset(r.URL.Query().Get("offset"))
+	if err != nil {
+		http.Error(w, "Invalid offset: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s := a.sessions.Current()
+	if s == nil {
+		http.Error(w, session.ErrNoStream.Error(), http.StatusConflict)
+		return
+	}
+
+	switch err := s.Seek(offset); {
+	case errors.Is(err, session.ErrDVRDisabled):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	case errors.Is(err, session.ErrStreamClosed):
+		http.Error(w, session.ErrNoStream.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, "Seek failed: "+err.Error(), http.StatusServiceUnavailable)
+	default:
+		log.Printf("Stream seek: offset %s", offset)
+		status := s.Status()
+		a.hub.Publish(hub.TypeStream, session.StreamEvent{Event: "seek", Status: &status})
+		writeRecordingJSON(w, http.StatusOK, status)
+	}
+}
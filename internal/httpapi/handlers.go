@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/session"
+	"webrtc-streamer/internal/subtitles"
+)
+
+func (a *API) handlePost(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	log.Printf("Stream request received")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.rejectClosing(w) {
+		return
+	}
+	if a.sessions.Busy() {
+		log.Printf("Stream blocked - already in progress")
+		http.Error(w, "Stream already in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Unable to read request", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var offer webrtc.SessionDescription
+	if err := decode(string(body), &offer); err != nil {
+		http.Error(w, "Bad offer", http.StatusBadRequest)
+		return
+	}
+	log.Printf("Received offer")
+
+	// ?offset=N 이면 N초 전부터 재생 (시간 이동 버퍼)
+	offset, err := parseSeekOffset(r.URL.Query().Get("offset"))
+	if err != nil {
+		http.Error(w, "Invalid offset: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// localhost/내부망 접속 감지
+	host := r.Host
+	isLocalhost := strings.Contains(host, "localhost") ||
+		strings.Contains(host, "127.0.0.1") ||
+		strings.Contains(host, "::1") ||
+		strings.Contains(host, "192.168.") ||
+		strings.Contains(host, "10.") ||
+		strings.Contains(host, "172.")
+
+	viewerID := a.hub.Presence().NewID()
+	answer, stream, err := a.sessions.Start(offer, session.StartOptions{
+		Localhost: isLocalhost,
+		Offset:    offset,
+		OnStart: func(*session.Stream) {
+			a.transcripts.StartSession()
+			a.hub.Presence().Join(viewerID, hub.PresenceWebRTC, r.URL.Query().Get("name"))
+		},
+		OnEnd: func() {
+			a.transcripts.EndSession()
+			a.hub.Presence().Leave(viewerID)
+			a.hub.Publish(hub.TypeStream, session.StreamEvent{Event: "stopped"})
+		},
+		OnLive: func(s *session.Stream) {
+			status := s.Status()
+			a.hub.Publish(hub.TypeStream, session.StreamEvent{Event: "live", Status: &status})
+		},
+	})
+	switch {
+	case errors.Is(err, session.ErrBusy):
+		http.Error(w, "Stream already in progress", http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, session.ErrClosed):
+		http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, session.ErrNegotiation):
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// SDP answer 반환
+	fmt.Fprint(w, encode(answer))
+	status := stream.Status()
+	a.hub.Publish(hub.TypeStream, session.StreamEvent{Event: "started", Status: &status})
+	log.Printf("Stream started (elapsed=%s)", time.Since(start))
+}
+
+func (a *API) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	a.sessions.Reset()
+	log.Printf("Stream state reset by client request (method: %s)", r.Method)
+	a.hub.Publish(hub.TypeAdmin, hub.AdminEvent{Event: "stream_reset"})
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK - Stream state reset"))
+}
+
+func (a *API) handleSubtitle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	subtitle, err := subtitles.Decode(r.Body)
+	if err == nil {
+		err = subtitles.Normalize(&subtitle, int(a.maxSubtitleTextLength.Load()))
+	}
+	if err != nil {
+		log.Printf("Rejected subtitle: %v", err)
+		http.Error(w, "Invalid subtitle: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.publishSubtitle(&subtitle); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// 검증된 자막에 발화 ID를 부여하고 WebSocket 브로드캐스트 및 자막 기록에 반영
+func (a *API) publishSubtitle(subtitle *subtitles.Data) error {
+	now := time.Now()
+	a.utterances.Assign(subtitle, now)
+
+	// 자막을 JSON으로 직렬화하여 WebSocket으로 브로드캐스트
+	if err := a.hub.PublishSubtitle(*subtitle); err != nil {
+		return err
+	}
+
+	a.transcripts.Add(*subtitle, now)
+	a.liveSubtitles.Add(*subtitle, now)
+	a.recordings.AddSubtitle(*subtitle, now)
+	a.translations.Submit(*subtitle)
+
+	if a.onSubtitle != nil {
+		a.onSubtitle(*subtitle)
+	}
+
+	log.Printf("Received subtitle: %s [%s] [Speaker %d] (%s #%d) %s", subtitle.LangCode, subtitle.Emoji, subtitle.Speaker, subtitle.UtteranceID, subtitle.Revision, subtitle.Text)
+	return nil
+}
+
+// HTTP를 거치지 않고 자막을 발행한다 (/subtitle과 같은 검증을 거친다)
+func (a *API) PublishSubtitle(subtitle subtitles.Data) error {
+	if err := subtitles.Normalize(&subtitle, int(a.maxSubtitleTextLength.Load())); err != nil {
+		return err
+	}
+	return a.publishSubtitle(&subtitle)
+}
+
+func (a *API) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := a.status()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
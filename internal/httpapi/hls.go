@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ---------- HLS HTTP ----------
+
+func (a *API) serveHLSPlaylist(w http.ResponseWriter, r *http.Request, render func() string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := a.hls.Touch(); err != nil {
+		http.Error(w, "Stream unavailable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if !a.hls.WaitReady(r.Context()) {
+		w.Header().Set("Retry-After", "2")
+		http.Error(w, "Stream not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	io.WriteString(w, render())
+}
+
+// GET /live/index.m3u8 - 영상과 라이브 자막을 묶은 마스터 재생 목록
+func (a *API) handleHLSMaster(w http.ResponseWriter, r *http.Request) {
+	a.serveHLSPlaylist(w, r, a.hls.MasterPlaylist)
+}
+
+// GET /live/video.m3u8
+func (a *API) handleHLSMedia(w http.ResponseWriter, r *http.Request) {
+	a.serveHLSPlaylist(w, r, a.hls.MediaPlaylist)
+}
+
+// GET /live/hls/init-<n>.mp4, /live/hls/<seq>.m4s
+func (a *API) handleHLSSegment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	a.hls.Touch()
+
+	name := strings.TrimPrefix(r.URL.Path, "/live/hls/")
+	var data []byte
+	var ok bool
+	contentType := "video/iso.segment"
+	if id, found := strings.CutPrefix(name, "init-"); found && strings.HasSuffix(id, ".mp4") {
+		n, err := strconv.Atoi(strings.TrimSuffix(id, ".mp4"))
+		if err == nil {
+			data, ok = a.hls.Init(n)
+		}
+		contentType = "video/mp4"
+	} else if seq, found := strings.CutSuffix(name, ".m4s"); found {
+		n, err := strconv.ParseInt(seq, 10, 64)
+		if err == nil {
+			data, ok = a.hls.Segment(n)
+		}
+	}
+	if !ok {
+		http.Error(w, "Segment not available", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(data)
+}
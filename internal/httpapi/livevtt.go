@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ---------- Live WebVTT HTTP ----------
+
+// GET /live/subtitles.m3u8
+func (a *API) handleLiveSubtitlePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	io.WriteString(w, a.liveSubtitles.Playlist("/live/subtitles/"))
+}
+
+// GET /live/subtitles/<n>.vtt
+func (a *API) handleLiveSubtitleSegment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/live/subtitles/")
+	n, err := strconv.ParseInt(strings.TrimSuffix(name, ".vtt"), 10, 64)
+	if err != nil || !strings.HasSuffix(name, ".vtt") {
+		http.Error(w, "Invalid segment", http.StatusBadRequest)
+		return
+	}
+
+	segment, ok := a.liveSubtitles.Segment(n)
+	if !ok {
+		http.Error(w, "Segment not available", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	io.WriteString(w, segment)
+}
@@ -0,0 +1,296 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/recording"
+)
+
+// ---------- Recording HTTP ----------
+
+func writeRecordingJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// GET /recording - 진행 중인 수동 녹화 상태
+func (a *API) handleRecordingStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, ok := a.recordings.Status()
+	if !ok {
+		writeRecordingJSON(w, http.StatusOK, map[string]bool{"active": false})
+		return
+	}
+	writeRecordingJSON(w, http.StatusOK, info)
+}
+
+// POST /recording/start
+func (a *API) handleRecordingStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, err := a.recordings.Start()
+	switch {
+	case errors.Is(err, recording.ErrActive):
+		writeRecordingJSON(w, http.StatusConflict, info)
+	case err != nil:
+		log.Printf("Recording start failed: %v", err)
+		http.Error(w, "Recording failed: "+err.Error(), http.StatusServiceUnavailable)
+	default:
+		a.hub.Publish(hub.TypeAdmin, hub.AdminEvent{Event: "recording_started", Data: info})
+		writeRecordingJSON(w, http.StatusOK, info)
+	}
+}
+
+// POST /recording/stop
+func (a *API) handleRecordingStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, err := a.recordings.Stop()
+	switch {
+	case errors.Is(err, recording.ErrInactive):
+		http.Error(w, "No recording in progress", http.StatusConflict)
+	case err != nil:
+		log.Printf("Recording stop failed: %v", err)
+		writeRecordingJSON(w, http.StatusInternalServerError, info)
+	default:
+		a.hub.Publish(hub.TypeAdmin, hub.AdminEvent{Event: "recording_stopped", Data: info})
+		writeRecordingJSON(w, http.StatusOK, info)
+	}
+}
+
+// GET /recording/continuous - 연속 녹화 및 보관 상태
+func (a *API) handleContinuousRecordingStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeRecordingJSON(w, http.StatusOK, a.recordings.ContinuousStatus())
+}
+
+// ---------- Catalog HTTP ----------
+
+// RFC 3339 또는 유닉스 초
+func parseQueryTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(sec*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// GET /recordings?stream=manual|continuous|clip&from=&to=&has_audio=1&transcript=1&limit=N
+func (a *API) handleRecordings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	var from, to time.Time
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := q.Get(p.key); v != "" {
+			t, err := parseQueryTime(v)
+			if err != nil {
+				http.Error(w, "Invalid "+p.key+": expected RFC 3339 or unix seconds", http.StatusBadRequest)
+				return
+			}
+			*p.dst = t
+		}
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	list, err := a.recordings.Catalog()
+	if err != nil {
+		log.Printf("Recording catalog failed: %v", err)
+		http.Error(w, "Recording catalog unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	filtered := make([]recording.Entry, 0, len(list))
+	for _, e := range list {
+		switch {
+		case q.Get("stream") != "" && e.Stream != q.Get("stream"):
+		case !from.IsZero() && e.EndedAt.Before(from):
+		case !to.IsZero() && e.StartedAt.After(to):
+		case q.Has("has_audio") && e.HasAudio != queryBool(q.Get("has_audio")):
+		case q.Has("transcript") && (len(e.Transcripts) > 0) != queryBool(q.Get("transcript")):
+		default:
+			filtered = append(filtered, e)
+		}
+		if limit > 0 && len(filtered) == limit {
+			break
+		}
+	}
+	writeRecordingJSON(w, http.StatusOK, filtered)
+}
+
+// GET|DELETE /recordings/<id>, GET /recordings/<id>/media (Range 요청 지원),
+// GET /recordings/<id>/subtitles.vtt
+func (a *API) handleRecordingItem(w http.ResponseWriter, r *http.Request) {
+	id, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/recordings/"), "/")
+
+	switch {
+	case r.Method == http.MethodDelete && resource == "":
+		switch err := a.recordings.Delete(id); {
+		case errors.Is(err, recording.ErrNotFound):
+			http.Error(w, "Recording not found", http.StatusNotFound)
+		case errors.Is(err, recording.ErrActive):
+			http.Error(w, "Recording in progress", http.StatusConflict)
+		case err != nil:
+			log.Printf("Recording delete failed: %v", err)
+			http.Error(w, "Delete failed", http.StatusInternalServerError)
+		default:
+			a.hub.Publish(hub.TypeAdmin, hub.AdminEvent{Event: "recording_deleted", Data: map[string]string{"id": id}})
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	e, err := a.recordings.Find(id)
+	if err != nil {
+		http.Error(w, "Recording not found", http.StatusNotFound)
+		return
+	}
+
+	var path, contentType string
+	switch resource {
+	case "":
+		writeRecordingJSON(w, http.StatusOK, e)
+		return
+	case "media":
+		path, contentType = e.MediaPath(), "video/mp4"
+	case "subtitles.vtt":
+		path, contentType = e.SubtitlesPath(), "text/vtt; charset=utf-8"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, "Recording not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Recording unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if e.Active {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	if queryBool(r.URL.Query().Get("download")) {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filepath.Base(path)+`"`)
+	}
+	http.ServeContent(w, r, filepath.Base(path), fi.ModTime(), f)
+}
+
+// ---------- Clip HTTP ----------
+
+// POST /clips?from=&to=
+// POST /clips?utterance=<발화 ID>[&before=15&after=15]
+// POST /clips?at=<시각>[&before=15&after=15]
+// 끝 시각이 미래면 그때까지 기다린 뒤 응답한다. 저장된 클립은 /recordings에 stream=clip으로 나타난다.
+func (a *API) handleClips(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	padding := map[string]time.Duration{"before": recording.ClipDefaultPadding, "after": recording.ClipDefaultPadding}
+	for key := range padding {
+		if v := q.Get(key); v != "" {
+			sec, err := strconv.ParseFloat(v, 64)
+			if err != nil || sec < 0 {
+				http.Error(w, "Invalid "+key+": expected seconds", http.StatusBadRequest)
+				return
+			}
+			padding[key] = time.Duration(sec * float64(time.Second))
+		}
+	}
+
+	var from, to time.Time
+	switch {
+	case q.Get("utterance") != "":
+		e, ok := a.transcripts.FindUtterance(q.Get("utterance"))
+		if !ok {
+			http.Error(w, "Utterance not found", http.StatusNotFound)
+			return
+		}
+		from, to = e.Received.Add(-padding["before"]), e.Received.Add(padding["after"])
+
+	case q.Get("at") != "":
+		at, err := parseQueryTime(q.Get("at"))
+		if err != nil {
+			http.Error(w, "Invalid at: expected RFC 3339 or unix seconds", http.StatusBadRequest)
+			return
+		}
+		from, to = at.Add(-padding["before"]), at.Add(padding["after"])
+
+	default:
+		var err error
+		if from, err = parseQueryTime(q.Get("from")); err != nil {
+			http.Error(w, "Invalid from: expected RFC 3339 or unix seconds", http.StatusBadRequest)
+			return
+		}
+		if to, err = parseQueryTime(q.Get("to")); err != nil {
+			http.Error(w, "Invalid to: expected RFC 3339 or unix seconds", http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch {
+	case !to.After(from):
+		http.Error(w, "Clip range is empty", http.StatusBadRequest)
+		return
+	case to.Sub(from) > recording.ClipMaxDuration:
+		http.Error(w, "Clip longer than "+recording.ClipMaxDuration.String(), http.StatusBadRequest)
+		return
+	case time.Until(to) > recording.ClipMaxWait:
+		http.Error(w, "Clip ends too far in the future", http.StatusBadRequest)
+		return
+	}
+
+	e, err := a.recordings.Clip(r.Context(), from, to)
+	switch {
+	case errors.Is(err, recording.ErrClipUnavailable):
+		http.Error(w, "No recorded media for the requested range", http.StatusNotFound)
+	case errors.Is(err, context.Canceled):
+		// 클라이언트가 기다리다 연결을 끊음
+	case err != nil:
+		log.Printf("Clip failed: %v", err)
+		http.Error(w, "Clip failed: "+err.Error(), http.StatusInternalServerError)
+	default:
+		log.Printf("Clip saved: %s (%.1fs, %d subtitles)", e.File, e.Duration, e.Subtitles)
+		a.hub.Publish(hub.TypeAdmin, hub.AdminEvent{Event: "clip_saved", Data: e})
+		writeRecordingJSON(w, http.StatusCreated, e)
+	}
+}
@@ -0,0 +1,64 @@
+package httpapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ---------- Snapshot HTTP ----------
+
+// GET /snapshot?format=jpeg|mp4|h264
+// 형식을 지정하지 않으면 변환 명령이 설정된 경우 JPEG, 아니면 MP4
+func (a *API) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	k, err := a.keyframes.Latest()
+	if err != nil {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Snapshot unavailable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "mp4"
+		if a.keyframes.CanDecode() {
+			format = "jpeg"
+		}
+	}
+
+	var data []byte
+	var contentType string
+	switch format {
+	case "h264":
+		data, contentType = k.AnnexB(), "video/h264"
+	case "mp4":
+		data, contentType = k.MP4(), "video/mp4"
+	case "jpeg", "jpg":
+		if !a.keyframes.CanDecode() {
+			http.Error(w, "JPEG snapshots require SNAPSHOT_JPEG_COMMAND", http.StatusNotImplemented)
+			return
+		}
+		if data, err = a.keyframes.JPEG(r.Context(), k); err != nil {
+			log.Printf("Snapshot decode failed: %v", err)
+			http.Error(w, "Snapshot decode failed", http.StatusInternalServerError)
+			return
+		}
+		contentType = "image/jpeg"
+	default:
+		http.Error(w, "Unsupported format (use jpeg, mp4 or h264)", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Last-Modified", k.CapturedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Snapshot-Time", k.CapturedAt.UTC().Format(time.RFC3339Nano))
+	w.Header().Set("X-Snapshot-Resolution", fmt.Sprintf("%dx%d", k.Width, k.Height))
+	w.Write(data)
+}
@@ -0,0 +1,179 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- Streaming subtitle ingest ----------
+
+// 한 메시지(NDJSON 한 줄 또는 WebSocket 메시지)의 최대 크기
+const maxSubtitleMessageSize = 64 * 1024
+
+// 생산자가 seq를 보내면 ack에 그대로 돌려주고, 없으면 연결 내 메시지 순번을 사용
+type subtitleIngestMessage struct {
+	Seq *uint64 `json:"seq"`
+	subtitles.Data
+}
+
+type subtitleAck struct {
+	Seq         uint64 `json:"seq"`
+	OK          bool   `json:"ok"`
+	UtteranceID string `json:"utterance_id,omitempty"`
+	Revision    int    `json:"revision,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// 메시지 하나를 검증/발행하고 ack를 만든다.
+// 발행은 순차적으로 이루어지므로 ack가 나가기 전까지 다음 메시지를 읽지 않는다 (backpressure).
+func (a *API) ingestSubtitleMessage(raw []byte, n uint64) subtitleAck {
+	var msg subtitleIngestMessage
+	err := json.Unmarshal(raw, &msg)
+	if msg.Seq != nil {
+		n = *msg.Seq
+	}
+	if err != nil {
+		return subtitleAck{Seq: n, Error: subtitles.DescribeJSONError(err).Error()}
+	}
+
+	subtitle := msg.Data
+	if err := subtitles.Normalize(&subtitle, int(a.maxSubtitleTextLength.Load())); err != nil {
+		return subtitleAck{Seq: n, Error: err.Error()}
+	}
+	if err := a.publishSubtitle(&subtitle); err != nil {
+		return subtitleAck{Seq: n, Error: "internal server error"}
+	}
+	return subtitleAck{Seq: n, OK: true, UtteranceID: subtitle.UtteranceID, Revision: subtitle.Revision}
+}
+
+// POST /subtitle/stream - 요청 본문의 NDJSON 한 줄마다 응답으로 ack 한 줄을 즉시 돌려준다.
+// 본문을 한 번에 보내면 일괄(batch) 전송으로도 사용할 수 있다.
+// 최대 크기를 넘는 줄은 오류 ack를 보낸 뒤 스트림을 끝낸다.
+func (a *API) handleSubtitleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// HTTP/1.1에서 본문을 다 읽기 전에 응답(ack)을 쓰기 위해 필요
+	rc := http.NewResponseController(w)
+	if err := rc.EnableFullDuplex(); err != nil && r.ProtoMajor == 1 {
+		log.Printf("Subtitle stream: full duplex unavailable: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	reader := bufio.NewReaderSize(r.Body, maxSubtitleMessageSize)
+	enc := json.NewEncoder(w)
+
+	var n, accepted uint64
+	for {
+		raw, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			// 줄 경계를 다시 찾을 수 없으므로 스트림을 끝낸다. seq는 잘린 앞부분에서 찾는다.
+			seq := n + 1
+			if s, ok := leadingSeq(raw); ok {
+				seq = s
+			}
+			enc.Encode(subtitleAck{Seq: seq, Error: fmt.Sprintf("message exceeds %d bytes, stream closed", maxSubtitleMessageSize)})
+			log.Printf("Subtitle stream ended: message exceeds %d bytes", maxSubtitleMessageSize)
+			break
+		}
+		if line := bytes.TrimSpace(raw); len(line) > 0 {
+			n++
+			ack := a.ingestSubtitleMessage(line, n)
+			if ack.OK {
+				accepted++
+			}
+			if err := enc.Encode(ack); err != nil {
+				return
+			}
+			rc.Flush()
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("Subtitle stream ended with error: %v", err)
+			}
+			break
+		}
+	}
+	log.Printf("Subtitle stream closed (%d/%d accepted)", accepted, n)
+}
+
+// 잘린 JSON 객체의 앞부분에 있는 최상위 "seq" 값을 찾는다
+func leadingSeq(prefix []byte) (uint64, bool) {
+	dec := json.NewDecoder(bytes.NewReader(prefix))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0, false
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0, false
+		}
+		if key == "seq" {
+			var seq uint64
+			if err := dec.Decode(&seq); err != nil {
+				return 0, false
+			}
+			return seq, true
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// WS /subtitle/ws - 텍스트 메시지 하나가 자막 하나, 메시지마다 ack를 돌려준다
+func (a *API) handleSubtitleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := a.producerUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Subtitle WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadLimit(maxSubtitleMessageSize)
+	log.Printf("Subtitle producer connected: %s", r.RemoteAddr)
+
+	var n, accepted uint64
+	for {
+		msgType, raw, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Subtitle WebSocket error: %v", err)
+			}
+			break
+		}
+		n++
+
+		var ack subtitleAck
+		if msgType != websocket.TextMessage {
+			ack = subtitleAck{Seq: n, Error: "expected a text message with subtitle JSON"}
+		} else {
+			ack = a.ingestSubtitleMessage(raw, n)
+		}
+		if ack.OK {
+			accepted++
+		}
+		if err := conn.WriteJSON(ack); err != nil {
+			break
+		}
+	}
+	log.Printf("Subtitle producer disconnected: %s (%d/%d accepted)", r.RemoteAddr, accepted, n)
+}
@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/recording"
+	"webrtc-streamer/internal/subtitles"
+)
+
+func newTestAPI(t *testing.T) *API {
+	t.Helper()
+	return New(Config{
+		Hub:                   hub.New(hub.Config{}),
+		Transcripts:           subtitles.NewTranscriptStore(1, nil),
+		LiveSubtitles:         subtitles.NewLiveTrack(subtitles.DefaultCueDuration, 1),
+		Utterances:            subtitles.NewUtteranceTracker(),
+		Recordings:            recording.NewManager(recording.Config{Dir: t.TempDir()}),
+		MaxSubtitleTextLength: 100,
+	})
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	newTestAPI(t).Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func readAck(t *testing.T, r *bufio.Reader) subtitleAck {
+	t.Helper()
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read ack: %v", err)
+	}
+	var ack subtitleAck
+	if err := json.Unmarshal(line, &ack); err != nil {
+		t.Fatalf("ack %q: %v", line, err)
+	}
+	return ack
+}
+
+// 본문을 다 보내기 전에 줄마다 ack가 돌아온다
+func TestSubtitleStreamAcksEachLine(t *testing.T) {
+	srv := newTestServer(t)
+	body, producer := io.Pipe()
+	defer producer.Close()
+	resp, err := http.Post(srv.URL+"/subtitle/stream", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	acks := bufio.NewReader(resp.Body)
+
+	for i, text := range []string{"하나", "둘"} {
+		fmt.Fprintf(producer, `{"seq": %d, "text": %q, "is_final": true}`+"\n", 10+i, text)
+		ack := readAck(t, acks)
+		if ack.Seq != uint64(10+i) || !ack.OK || ack.UtteranceID == "" {
+			t.Errorf("ack %d = %+v, want ok with seq %d", i, ack, 10+i)
+		}
+	}
+}
+
+func TestSubtitleStreamBatch(t *testing.T) {
+	long := strings.Repeat("가", maxSubtitleMessageSize)
+	tests := []struct {
+		name string
+		body string
+		want []subtitleAck
+	}{
+		{
+			name: "잘못된 줄 다음의 줄도 처리",
+			body: "{\"text\": \"\"}\n\nnot json\n{\"text\": \"ok\", \"is_final\": true}",
+			want: []subtitleAck{
+				{Seq: 1, Error: "text is required"},
+				{Seq: 2, Error: "*"},
+				{Seq: 3, OK: true},
+			},
+		},
+		{
+			name: "너무 긴 줄은 생산자 seq로 거절하고 스트림을 끝낸다",
+			body: "{\"text\": \"ok\"}\n{\"seq\": 42, \"text\": \"" + long + "\"}\n{\"text\": \"after\"}\n",
+			want: []subtitleAck{
+				{Seq: 1, OK: true},
+				{Seq: 42, Error: "message exceeds 65536 bytes, stream closed"},
+			},
+		},
+		{
+			name: "seq가 없는 너무 긴 줄은 연결 내 순번",
+			body: "{\"text\": \"" + long + "\", \"seq\": 42}\n",
+			want: []subtitleAck{
+				{Seq: 1, Error: "message exceeds 65536 bytes, stream closed"},
+			},
+		},
+	}
+	srv := newTestServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/subtitle/stream", "application/x-ndjson", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			acks := bufio.NewReader(resp.Body)
+			for i, want := range tt.want {
+				got := readAck(t, acks)
+				if got.Seq != want.Seq || got.OK != want.OK || (want.Error != "*" && got.Error != want.Error) ||
+					(want.Error == "*" && got.Error == "") {
+					t.Errorf("ack %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if rest, _ := io.ReadAll(acks); len(rest) != 0 {
+				t.Errorf("extra acks: %s", rest)
+			}
+		})
+	}
+}
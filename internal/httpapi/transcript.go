@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- Transcript HTTP ----------
+
+func (a *API) handleTranscripts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.transcripts.List())
+}
+
+// GET /transcript?session=<id|latest>&format=vtt|srt|txt|json&speaker=1&emotion=1
+func (a *API) handleTranscript(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	sess, ok := a.transcripts.Get(q.Get("session"))
+	if !ok {
+		http.Error(w, "Transcript session not found", http.StatusNotFound)
+		return
+	}
+
+	opts := subtitles.TranscriptOptions{
+		Speakers: queryBool(q.Get("speaker")),
+		Emotions: queryBool(q.Get("emotion")),
+	}
+
+	format := q.Get("format")
+	if format == "" {
+		format = "vtt"
+	}
+
+	var contentType string
+	var write func(io.Writer) error
+	cues := sess.Cues()
+	switch format {
+	case "vtt":
+		contentType = "text/vtt; charset=utf-8"
+		write = func(w io.Writer) error { return subtitles.WriteWebVTT(w, cues, opts) }
+	case "srt":
+		contentType = "application/x-subrip; charset=utf-8"
+		write = func(w io.Writer) error { return subtitles.WriteSRT(w, cues, opts) }
+	case "txt":
+		contentType = "text/plain; charset=utf-8"
+		write = func(w io.Writer) error { return subtitles.WritePlainTranscript(w, cues, opts) }
+	case "json":
+		contentType = "application/json"
+		write = func(w io.Writer) error { return subtitles.WriteJSONTranscript(w, sess, cues, opts) }
+	default:
+		http.Error(w, "Unsupported format (use vtt, srt, txt or json)", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="transcript-%s.%s"`, sess.ID, format))
+	if err := write(w); err != nil {
+		log.Printf("Failed to write transcript %s: %v", sess.ID, err)
+	}
+}
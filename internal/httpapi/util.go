@@ -1,31 +1,13 @@
-package main
+package httpapi
 
 import (
 	"encoding/base64"
 	"encoding/json"
-	"os"
-	"strconv"
+	"strings"
 )
 
 // ---------- helpers ----------
 
-func getenvStr(k, d string) string {
-	v := os.Getenv(k)
-	if v == "" {
-		return d
-	}
-	return v
-}
-
-func getenvInt(k string, d int) int {
-	if s := os.Getenv(k); s != "" {
-		if n, err := strconv.Atoi(s); err == nil {
-			return n
-		}
-	}
-	return d
-}
-
 // encode: JSON -> base64 string
 func encode(v any) string {
 	b, _ := json.Marshal(v)
@@ -40,3 +22,12 @@ func decode(s string, v any) error {
 	}
 	return json.Unmarshal(raw, v)
 }
+
+// 쿼리 파라미터 불리언 해석 (1, true, yes, on)
+func queryBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
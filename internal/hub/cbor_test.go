@@ -0,0 +1,155 @@
+package hub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// 테스트용 CBOR 디코더 - jsonToCBOR가 쓰는 형식만 읽는다
+func decodeCBOR(r *bytes.Reader) (any, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch b {
+	case cborFalse:
+		return false, nil
+	case cborTrue:
+		return true, nil
+	case cborNull:
+		return nil, nil
+	case cborFloat64:
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	}
+
+	n := uint64(b & 0x1F)
+	switch n {
+	case 24, 25, 26, 27:
+		size := 1 << (n - 24)
+		buf := make([]byte, 8)
+		if _, err := r.Read(buf[8-size:]); err != nil {
+			return nil, err
+		}
+		n = binary.BigEndian.Uint64(buf)
+	}
+	switch b & 0xE0 {
+	case cborUint:
+		return float64(n), nil
+	case cborNegInt:
+		return -1 - float64(n), nil
+	case cborText:
+		s := make([]byte, n)
+		if _, err := r.Read(s); err != nil && n > 0 {
+			return nil, err
+		}
+		return string(s), nil
+	case cborArray:
+		a := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			v, err := decodeCBOR(r)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	case cborMap:
+		m := make(map[string]any, n)
+		for i := uint64(0); i < n; i++ {
+			k, err := decodeCBOR(r)
+			if err != nil {
+				return nil, err
+			}
+			if m[k.(string)], err = decodeCBOR(r); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unexpected CBOR byte %#x", b)
+}
+
+func TestJSONToCBORRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"type":"subtitle","v":1,"seq":70000,"ts":1760000000000,"payload":{"text":"안녕하세요","is_final":true,"confidence":0.93,"words":null}}`,
+		`[0,23,24,255,256,65535,65536,4294967296,-1,-24,-25,-300]`,
+		`{"nested":[{"a":[]},{},"` + string(bytes.Repeat([]byte("x"), 300)) + `"],"f":false,"pi":-3.5}`,
+	}
+	for _, in := range tests {
+		out, err := jsonToCBOR([]byte(in))
+		if err != nil {
+			t.Fatalf("jsonToCBOR(%s): %v", in, err)
+		}
+		r := bytes.NewReader(out)
+		got, err := decodeCBOR(r)
+		if err != nil {
+			t.Fatalf("decode %s: %v", in, err)
+		}
+		if r.Len() != 0 {
+			t.Errorf("%s: %d trailing bytes", in, r.Len())
+		}
+		var want any
+		json.Unmarshal([]byte(in), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of %s = %v", in, got)
+		}
+	}
+
+	// 정수는 가장 짧은 헤드로, 맵 키는 정렬해서 기록한다
+	out, _ := jsonToCBOR([]byte(`{"b":1,"a":500}`))
+	if want := []byte{0xA2, 0x61, 'a', 0x19, 0x01, 0xF4, 0x61, 'b', 0x01}; !bytes.Equal(out, want) {
+		t.Errorf("jsonToCBOR = % x, want % x", out, want)
+	}
+	if _, err := jsonToCBOR([]byte(`{"broken"`)); err == nil {
+		t.Error("jsonToCBOR of invalid JSON succeeded")
+	}
+}
+
+// CBOR로 바꿀 수 없는 메시지는 바이너리 클라이언트에게 보내지 않고 버린 것으로 센다.
+// 실패는 한 번만 변환해 다른 클라이언트와 나눠 쓴다.
+func TestEnqueueSkipsUnencodableMessage(t *testing.T) {
+	h := New(Config{})
+	newClient := func(binary bool) *Client {
+		c := h.newClient(newFakeTransport(), httptest.NewRequest("GET", "/ws", nil))
+		c.binary = binary
+		return c
+	}
+	msg := hubMessage{topic: topicSubtitle, finalKey: "u1", data: []byte(`{"broken"`), cbor: new(cborCache)}
+
+	c := newClient(true)
+	c.enqueue(hubMessage{topic: topicSubtitle, partialKey: "u1", data: []byte(`{}`), cbor: new(cborCache)}, 8)
+	if !c.enqueue(msg, 8) {
+		t.Fatal("client disconnected on an encoding failure")
+	}
+	if len(c.queue) != 0 || len(c.partialIDs) != 0 {
+		t.Errorf("queue = %d, partials = %v; want the final skipped and its partial removed", len(c.queue), c.partialIDs)
+	}
+	if c.drops != (MessageDrops{Messages: 1}) || !c.dropNotice {
+		t.Errorf("drops = %+v, want 1 message with a notice", c.drops)
+	}
+	if msg.cbor.err == nil {
+		t.Error("encoding failure not cached")
+	}
+
+	c2 := newClient(true)
+	c2.enqueue(msg, 8)
+	if len(c2.queue) != 0 || c2.drops.Messages != 1 {
+		t.Errorf("second binary client queue = %d, drops = %+v", len(c2.queue), c2.drops)
+	}
+	// JSON 클라이언트는 원래 데이터를 그대로 받는다
+	text := newClient(false)
+	text.enqueue(msg, 8)
+	if len(text.queue) != 1 || text.drops != (MessageDrops{}) {
+		t.Errorf("JSON client queue = %d, drops = %+v; want the message", len(text.queue), text.drops)
+	}
+}
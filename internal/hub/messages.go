@@ -0,0 +1,112 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- WebSocket messages ----------
+
+// /ws로 보내는 모든 메시지는 WSMessage 봉투에 담긴다.
+// 호환되지 않는 변경이 있을 때만 버전을 올리고, 클라이언트는 모르는 type을 무시한다.
+const wsProtocolVersion = 1
+
+const (
+	TypeSubtitle = "subtitle" // subtitles.Data
+	TypeStatus   = "status"   // sysstatus.Status
+	TypeStream   = "stream"   // session.StreamEvent
+	TypeSession  = "session"  // subtitles.SessionEvent
+	TypeAdmin    = "admin"    // AdminEvent
+	TypePresence = "presence" // PresenceEvent
+
+	wsTypeSubscriptions = "subscriptions" // SubscriptionsInfo (구독을 바꾼 클라이언트에게만)
+	wsTypeNotice        = "notice"        // ClientNotice (전송이 밀린 클라이언트에게만)
+)
+
+type WSMessage struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Payload any    `json:"payload"`
+	Seq     uint64 `json:"seq"` // 서버 전체에서 증가 (병합된 부분 자막만큼 비어 있을 수 있음)
+	TS      int64  `json:"ts"`  // 유닉스 밀리초
+}
+
+// 운영 이벤트 (admin 토픽)
+type AdminEvent struct {
+	Event string `json:"event"` // recording_started | recording_stopped | recording_deleted | clip_saved | stream_reset
+	Data  any    `json:"data,omitempty"`
+}
+
+// 전송 대기열이 차서 메시지를 버렸거나 연결을 끊을 때 (누적 수)
+type ClientNotice struct {
+	Event   string       `json:"event"` // dropped | disconnect
+	Dropped MessageDrops `json:"dropped"`
+	Reason  string       `json:"reason,omitempty"`
+}
+
+// 토픽은 메시지 종류와 같다. 언어별 자막처럼 세분화할 때는 반환값의 topic을 바꾼다.
+func (h *Hub) message(typ string, payload any) (hubMessage, error) {
+	seq := h.seq.Add(1)
+	data, err := json.Marshal(WSMessage{
+		Type:    typ,
+		Version: wsProtocolVersion,
+		Payload: payload,
+		Seq:     seq,
+		TS:      time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return hubMessage{}, err
+	}
+	return hubMessage{data: data, cbor: new(cborCache), seq: seq, topic: typ}, nil
+}
+
+// 언어별 자막 토픽으로 보낸다. 부분 자막은 같은 발화의 최신 것만 남기고,
+// 서버가 번역한 자막은 그 언어를 요청한 클라이언트에게만 간다.
+func (h *Hub) PublishSubtitle(subtitle subtitles.Data) error {
+	msg, err := h.message(TypeSubtitle, subtitle)
+	if err != nil {
+		log.Printf("Failed to marshal subtitle: %v", err)
+		return err
+	}
+	msg.topic, msg.lang = subtitleTopic(subtitle.LangCode), subtitle.LangCode
+	switch {
+	case subtitle.TranslatedFrom != "":
+		msg.translated = true
+	case subtitle.IsFinal:
+		msg.finalKey = subtitle.UtteranceID
+	default:
+		msg.partialKey = subtitle.UtteranceID
+	}
+	h.send(msg)
+	return nil
+}
+
+// 해당 토픽을 구독한 클라이언트에게 보낸다
+func (h *Hub) Publish(typ string, payload any) {
+	msg, err := h.message(typ, payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s message: %v", typ, err)
+		return
+	}
+	h.send(msg)
+}
+
+// 접속한 클라이언트가 있으면 주기적으로 status()를 보낸다 (ctx가 끝나면 멈춤)
+func (h *Hub) StatusLoop(ctx context.Context, interval time.Duration, status func() any) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if h.connected.Load() > 0 {
+				h.Publish(TypeStatus, status())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package hub
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// 클라이언트 전송 방식 (WebSocket, SSE)
+type clientTransport interface {
+	Name() string
+	WriteBatch(messages [][]byte) error
+	Ping() error
+	Close(code int, reason string) // 서버가 끊을 때의 닫기 코드와 이유 (0이면 정상 종료)
+}
+
+type Client struct {
+	hub       *Hub
+	transport clientTransport
+	remote    string
+	binary    bool // CBOR 인코딩
+
+	presenceID string
+	name       string // 표시 이름 (?name=)
+	privileged bool   // 운영용 토픽을 구독할 수 있음 (접속할 때 인증)
+
+	// 등록 시 이 순번 이후의 기록을 먼저 보낸다 (SSE Last-Event-ID)
+	resumeAfter uint64
+
+	// 구독 중인 토픽과 원하는 자막 언어 코드 (hub.run에서만 접근)
+	topics    map[string]bool
+	languages map[string]bool
+
+	// 전송 대기열 - hub.run이 넣고 writePump가 꺼낸다
+	mu          sync.Mutex
+	queue       []clientMessage
+	partials    map[string][]byte // utterance별 최신 부분 자막
+	partialIDs  []string
+	drops       MessageDrops
+	dropNotice  bool   // 버린 메시지가 있음을 아직 알리지 않음
+	closed      bool   // hub에서 빠짐
+	closeCode   int    // 서버가 끊는 경우의 닫기 코드
+	closeReason string // 서버가 끊는 이유 (느린 클라이언트, 서버 종료)
+	wake        chan struct{}
+	partialWake chan struct{}
+	done        chan struct{} // writePump가 끝나면 닫힌다
+}
+
+type hubShutdown struct {
+	reason string
+	done   chan []chan struct{} // 닫은 클라이언트들의 writePump 종료
+}
+
+type clientMessage struct {
+	data      []byte
+	droppable bool
+}
+
+// 대기열이 차서 버린 메시지 수
+type MessageDrops struct {
+	Partials uint64 `json:"partials"`
+	Messages uint64 `json:"messages"`
+}
+
+// partialKey가 있으면 클라이언트별로 같은 발화의 부분 자막을 병합한다
+type hubMessage struct {
+	data       []byte
+	cbor       *cborCache // CBOR 인코딩 - 복사본끼리 공유하며 처음 필요할 때 만든다 (hub.run에서만 접근)
+	seq        uint64
+	topic      string
+	lang       string // 자막의 언어 코드 - 클라이언트 언어 설정으로 거른다
+	translated bool
+	partialKey string
+	finalKey   string
+}
+
+// 실패도 기록해 클라이언트마다 다시 변환하지 않는다
+type cborCache struct {
+	data []byte
+	err  error
+}
+
+// 대기열이 차면 버릴 수 있는 메시지 - 곧 새 값으로 대체되는 부분 자막과 상태.
+// 최종 자막과 송출/세션/운영 이벤트는 버리지 않는다.
+func (m hubMessage) droppable() bool {
+	return m.partialKey != "" || m.topic == topicStatus
+}
+
+type Hub struct {
+	presence *Presence
+	upgrader websocket.Upgrader
+
+	clients    map[*Client]bool
+	register   chan *Client
+	unregister chan *Client
+	subscribe  chan subscriptionChange
+	stats      chan chan HubStats
+	shutdown   chan hubShutdown
+	closing    string // 종료 중이면 끊는 이유 (hub.run에서만 접근)
+
+	// 발행 대기열 - 발행하는 쪽은 hub.run을 기다리지 않는다
+	inMu    sync.Mutex
+	in      []hubMessage
+	inWake  chan struct{}
+	inDrops MessageDrops
+
+	// 최근에 보낸 버릴 수 없는 메시지 - 재접속한 클라이언트에게 다시 보낸다 (hub.run에서만 접근)
+	history     []hubMessage
+	historySize int
+
+	authorize func(r *http.Request) error
+
+	queueSize       int
+	clientQueueSize int
+	partialInterval atomic.Int64 // time.Duration, 실행 중에 바꿀 수 있다
+
+	seq       atomic.Uint64 // WSMessage.seq
+	connected atomic.Int32
+	languages atomic.Pointer[[]string] // 클라이언트들이 원하는 자막 언어 (번역 대상)
+}
@@ -0,0 +1,173 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ---------- Presence ----------
+
+const (
+	PresenceWebSocket = "websocket"
+	PresenceSSE       = "sse"
+	PresenceWebRTC    = "webrtc"
+
+	maxPresenceNameLength = 64
+)
+
+type PresenceMember struct {
+	ID       string    `json:"id"`
+	Kind     string    `json:"kind"` // websocket | sse | webrtc
+	Name     string    `json:"name,omitempty"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type PresenceCounts struct {
+	Total     int `json:"total"`
+	WebSocket int `json:"websocket"`
+	SSE       int `json:"sse"`
+	WebRTC    int `json:"webrtc"` // 영상 시청자
+}
+
+// presence 토픽으로 보내는 입장/퇴장/이름 변경
+type PresenceEvent struct {
+	Event  string         `json:"event"` // join | leave | update
+	Member PresenceMember `json:"member"`
+	Counts PresenceCounts `json:"counts"`
+}
+
+// 접속한 WebSocket/SSE 클라이언트와 WebRTC 시청자 목록
+type Presence struct {
+	hub     *Hub // 변경을 presence 토픽으로 알린다
+	mu      sync.Mutex
+	prefix  string
+	seq     uint64
+	members map[string]*PresenceMember
+}
+
+func newPresence(h *Hub) *Presence {
+	return &Presence{
+		hub:     h,
+		prefix:  strconv.FormatInt(time.Now().Unix(), 36),
+		members: make(map[string]*PresenceMember),
+	}
+}
+
+func (p *Presence) NewID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	return fmt.Sprintf("%s-%d", p.prefix, p.seq)
+}
+
+// 제어 문자를 빼고 길이를 제한한 표시 이름
+func presenceName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > maxPresenceNameLength {
+		name = string(r[:maxPresenceNameLength])
+	}
+	return name
+}
+
+func (p *Presence) Join(id, kind, name string) {
+	m := &PresenceMember{ID: id, Kind: kind, Name: presenceName(name), JoinedAt: time.Now()}
+	p.mu.Lock()
+	p.members[id] = m
+	event := p.eventLocked("join", m)
+	p.mu.Unlock()
+	p.hub.Publish(TypePresence, event)
+}
+
+func (p *Presence) Leave(id string) {
+	p.mu.Lock()
+	m, ok := p.members[id]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.members, id)
+	event := p.eventLocked("leave", m)
+	p.mu.Unlock()
+	p.hub.Publish(TypePresence, event)
+}
+
+func (p *Presence) Rename(id, name string) {
+	p.mu.Lock()
+	m, ok := p.members[id]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	m.Name = presenceName(name)
+	event := p.eventLocked("update", m)
+	p.mu.Unlock()
+	p.hub.Publish(TypePresence, event)
+}
+
+func (p *Presence) eventLocked(event string, m *PresenceMember) PresenceEvent {
+	return PresenceEvent{Event: event, Member: *m, Counts: p.countsLocked()}
+}
+
+func (p *Presence) countsLocked() PresenceCounts {
+	c := PresenceCounts{Total: len(p.members)}
+	for _, m := range p.members {
+		switch m.Kind {
+		case PresenceWebSocket:
+			c.WebSocket++
+		case PresenceSSE:
+			c.SSE++
+		case PresenceWebRTC:
+			c.WebRTC++
+		}
+	}
+	return c
+}
+
+func (p *Presence) Counts() PresenceCounts {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.countsLocked()
+}
+
+// 입장 순서
+func (p *Presence) List() []PresenceMember {
+	p.mu.Lock()
+	list := make([]PresenceMember, 0, len(p.members))
+	for _, m := range p.members {
+		list = append(list, *m)
+	}
+	p.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].JoinedAt.Before(list[j].JoinedAt) })
+	return list
+}
+
+// GET /presence - 접속자 목록과 종류별 수 (presence 토픽과 같은 인증 필요)
+func (p *Presence) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if p.hub.authorize != nil {
+		if err := p.hub.authorize(r); err != nil {
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Counts  PresenceCounts   `json:"counts"`
+		Members []PresenceMember `json:"members"`
+	}{p.Counts(), p.List()})
+}
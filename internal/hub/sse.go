@@ -0,0 +1,94 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- Server-Sent Events ----------
+
+// WebSocket을 쓸 수 없는 브라우저/프록시용. /ws와 같은 봉투를 이벤트 하나에 하나씩 보내며,
+// 이벤트 이름은 메시지 type, id는 seq이다.
+type sseTransport struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (t *sseTransport) Name() string { return "sse" }
+
+func (t *sseTransport) WriteBatch(messages [][]byte) error {
+	t.rc.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	for _, message := range messages {
+		var head struct {
+			Type string `json:"type"`
+			Seq  uint64 `json:"seq"`
+		}
+		json.Unmarshal(message, &head)
+		if _, err := fmt.Fprintf(t.w, "id: %d\nevent: %s\ndata: %s\n\n", head.Seq, head.Type, message); err != nil {
+			return err
+		}
+	}
+	return t.rc.Flush()
+}
+
+// 프록시가 유휴 연결을 끊지 않도록 주석 줄을 보낸다
+func (t *sseTransport) Ping() error {
+	t.rc.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := fmt.Fprint(t.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	return t.rc.Flush()
+}
+
+// 핸들러가 반환하면 응답이 끝난다. EventSource는 Last-Event-ID로 다시 접속한다.
+func (t *sseTransport) Close(code int, reason string) {}
+
+// GET /events[?topics=&languages=&last_event_id=]
+// 다시 접속할 때 Last-Event-ID 헤더(또는 last_event_id)보다 뒤의 자막과 이벤트를 최근 기록에서 다시 보낸다.
+func (h *Hub) ServeEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var resumeAfter uint64
+	if lastID != "" {
+		n, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		resumeAfter = n
+	}
+
+	t := &sseTransport{w: w, rc: http.NewResponseController(w)}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx 버퍼링 끔
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := t.rc.Flush(); err != nil {
+		log.Printf("SSE: streaming unsupported: %v", err)
+		return
+	}
+
+	client := h.newClient(t, r)
+	client.resumeAfter = resumeAfter
+	h.register <- client
+
+	// 연결이 끊기면 hub에서 빼고, writePump는 hub가 닫은 뒤 끝난다
+	go func() {
+		<-r.Context().Done()
+		h.unregister <- client
+	}()
+	client.writePump()
+	log.Printf("SSE client disconnected")
+}
@@ -0,0 +1,101 @@
+package hub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+// Run이 발행 대기열을 모두 처리할 때까지 기다린다
+func waitPublished(t *testing.T, h *Hub) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		reply := make(chan HubStats, 1)
+		h.stats <- reply
+		if (<-reply).Queued == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("publish queue not drained")
+}
+
+// SSE 응답에서 이벤트의 "id 이벤트이름"을 차례로 넘긴다
+func readEvents(body *bufio.Reader) <-chan string {
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		id := ""
+		for {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				events <- id + " " + strings.TrimPrefix(line, "event: ")
+			}
+		}
+	}()
+	return events
+}
+
+// Last-Event-ID로 다시 접속하면 그 뒤의 최종 자막을 기록에서 다시 받고, 이어서 새 메시지를 받는다
+func TestServeEventsResume(t *testing.T) {
+	h := New(Config{QueueSize: 8, ClientQueueSize: 8, HistorySize: 8})
+	go h.Run()
+	srv := httptest.NewServer(http.HandlerFunc(h.ServeEvents))
+	defer srv.Close()
+
+	h.PublishSubtitle(subtitles.Data{Text: "1", LangCode: "KR", IsFinal: true})
+	h.PublishSubtitle(subtitles.Data{Text: "2", LangCode: "KR", UtteranceID: "u3"}) // 부분 자막은 기록하지 않는다
+	h.PublishSubtitle(subtitles.Data{Text: "3", LangCode: "KR", IsFinal: true})
+	h.PublishSubtitle(subtitles.Data{Text: "4", LangCode: "KR", IsFinal: true})
+	waitPublished(t, h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	events := readEvents(bufio.NewReader(resp.Body))
+
+	for _, want := range []string{"3 subtitle", "4 subtitle"} {
+		if got := <-events; got != want {
+			t.Fatalf("replayed event %q, want %q", got, want)
+		}
+	}
+	// 접속 알림(presence)도 순번을 쓰므로 새 자막의 id는 5 이상이다
+	h.PublishSubtitle(subtitles.Data{Text: "5", LangCode: "KR", IsFinal: true})
+	var id int
+	var event string
+	if _, err := fmt.Sscanf(<-events, "%d %s", &id, &event); err != nil || id < 5 || event != "subtitle" {
+		t.Errorf("live event #%d %q (%v), want a new subtitle", id, event, err)
+	}
+
+	resp, err = http.Get(srv.URL + "?last_event_id=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid last_event_id status = %d, want 400", resp.StatusCode)
+	}
+}
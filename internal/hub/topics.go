@@ -0,0 +1,240 @@
+package hub
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- WebSocket topics ----------
+
+// 메시지는 토픽 하나에 속하고, 클라이언트는 구독한 토픽의 메시지만 받는다.
+// 토픽은 "/"로 나뉘며 상위 토픽을 구독하면 하위 토픽도 받는다 (subtitle → subtitle/kr).
+const (
+	topicSubtitle = "subtitle" // subtitle/<언어 코드>
+	topicStatus   = "status"
+	topicStream   = "stream"
+	topicSession  = "session"
+	topicAdmin    = "admin" // 녹화/클립/스트림 초기화 등 운영 이벤트
+	topicPresence = "presence"
+)
+
+// 접속할 때 인증을 통과한 클라이언트만 구독할 수 있는 토픽
+var privilegedTopics = map[string]bool{topicAdmin: true, topicPresence: true}
+
+// 구독을 지정하지 않은 클라이언트의 기본 구독 (admin, presence 제외)
+var defaultTopics = []string{topicSubtitle, topicStatus, topicStream, topicSession}
+
+const maxClientTopics = 32
+
+// 클라이언트 → 서버 메시지
+const (
+	wsClientSubscribe   = "subscribe"
+	wsClientUnsubscribe = "unsubscribe"
+	wsClientLanguages   = "languages"
+	wsClientPresence    = "presence"
+)
+
+type wsClientMessage struct {
+	Type      string   `json:"type"`
+	Topics    []string `json:"topics"`
+	Languages []string `json:"languages"`
+	Name      string   `json:"name"`
+}
+
+// 구독 변경 응답 (wsTypeSubscriptions)
+type SubscriptionsInfo struct {
+	Topics    []string `json:"topics"`
+	Languages []string `json:"languages,omitempty"`
+	Rejected  []string `json:"rejected,omitempty"`
+}
+
+type subscriptionChange struct {
+	client   *Client
+	add      []string
+	remove   []string
+	rejected []string
+
+	setLanguages bool
+	languages    []string
+}
+
+// 언어별 자막 토픽
+func subtitleTopic(langCode string) string {
+	if langCode == "" {
+		return topicSubtitle
+	}
+	return topicSubtitle + "/" + strings.ToLower(langCode)
+}
+
+// 알려진 토픽이면 정규화한 이름을 반환
+func normalizeTopic(topic string) (string, bool) {
+	topic = strings.ToLower(strings.Trim(strings.TrimSpace(topic), "/"))
+	root, sub, nested := strings.Cut(topic, "/")
+	switch root {
+	case topicSubtitle:
+		if nested && (sub == "" || strings.Contains(sub, "/")) {
+			return "", false
+		}
+		return topic, true
+	case topicStatus, topicStream, topicSession, topicAdmin, topicPresence:
+		return topic, !nested
+	}
+	return "", false
+}
+
+func parseTopics(topics []string) (valid, rejected []string) {
+	for _, t := range topics {
+		if n, ok := normalizeTopic(t); ok {
+			valid = append(valid, n)
+		} else {
+			rejected = append(rejected, t)
+		}
+	}
+	return valid, rejected
+}
+
+// 권한이 없으면 운영용 토픽을 뺀다
+func allowedTopics(topics []string, privileged bool) (allowed, rejected []string) {
+	if privileged {
+		return topics, nil
+	}
+	for _, t := range topics {
+		if privilegedTopics[t] {
+			rejected = append(rejected, t)
+		} else {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed, rejected
+}
+
+// 언어 이름이나 코드를 언어 코드로 (ko, KR → KR)
+func parseLanguages(languages []string) (codes, rejected []string) {
+	for _, l := range languages {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if _, code, err := subtitles.CanonicalLanguage("", l); err == nil {
+			codes = append(codes, code)
+		} else {
+			rejected = append(rejected, l)
+		}
+	}
+	return codes, rejected
+}
+
+// 구독이 메시지 토픽과 같거나 상위 토픽이면 전달한다.
+// 자막은 원하는 언어를 정한 클라이언트에게 그 언어만, 정하지 않은 클라이언트에게는 번역본을 뺀 원본만 보낸다.
+func (c *Client) wants(msg hubMessage) bool {
+	if msg.lang != "" {
+		if len(c.languages) > 0 && !c.languages[msg.lang] {
+			return false
+		}
+		if len(c.languages) == 0 && msg.translated {
+			return false
+		}
+	}
+	for t := range c.topics {
+		if t == msg.topic || strings.HasPrefix(msg.topic, t+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// 클라이언트들이 원하는 자막 언어 (번역 대상)
+func (h *Hub) Languages() []string {
+	if l := h.languages.Load(); l != nil {
+		return *l
+	}
+	return nil
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) updateLanguages() {
+	set := make(map[string]bool)
+	for c := range h.clients {
+		for l := range c.languages {
+			set[l] = true
+		}
+	}
+	list := make([]string, 0, len(set))
+	for l := range set {
+		list = append(list, l)
+	}
+	sort.Strings(list)
+	h.languages.Store(&list)
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) applySubscription(ch subscriptionChange) {
+	c := ch.client
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	for _, t := range ch.remove {
+		delete(c.topics, t)
+	}
+	for _, t := range ch.add {
+		if len(c.topics) >= maxClientTopics {
+			ch.rejected = append(ch.rejected, t)
+			continue
+		}
+		c.topics[t] = true
+	}
+	if ch.setLanguages {
+		c.languages = make(map[string]bool)
+		for _, l := range ch.languages {
+			c.languages[l] = true
+		}
+		h.updateLanguages()
+	}
+
+	info := subscriptionsOf(c)
+	info.Rejected = ch.rejected
+	msg, err := h.message(wsTypeSubscriptions, info)
+	if err != nil {
+		return
+	}
+	h.deliver(c, msg)
+}
+
+// hub.run 안에서만 호출된다
+func subscriptionsOf(c *Client) SubscriptionsInfo {
+	info := SubscriptionsInfo{Topics: make([]string, 0, len(c.topics))}
+	for t := range c.topics {
+		info.Topics = append(info.Topics, t)
+	}
+	sort.Strings(info.Topics)
+	for l := range c.languages {
+		info.Languages = append(info.Languages, l)
+	}
+	sort.Strings(info.Languages)
+	return info
+}
+
+// readPump가 받은 클라이언트 메시지 처리. 모르는 메시지는 무시한다.
+func (c *Client) handleMessage(data []byte) {
+	var m wsClientMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return
+	}
+	topics, rejected := parseTopics(m.Topics)
+	switch m.Type {
+	case wsClientLanguages:
+		// 빈 목록이면 모든 언어의 원본 자막
+		languages, rejected := parseLanguages(m.Languages)
+		c.hub.subscribe <- subscriptionChange{client: c, setLanguages: true, languages: languages, rejected: rejected}
+	case wsClientPresence:
+		c.hub.presence.Rename(c.presenceID, m.Name)
+	case wsClientSubscribe:
+		topics, denied := allowedTopics(topics, c.privileged)
+		c.hub.subscribe <- subscriptionChange{client: c, add: topics, rejected: append(rejected, denied...)}
+	case wsClientUnsubscribe:
+		c.hub.subscribe <- subscriptionChange{client: c, remove: topics, rejected: rejected}
+	}
+}
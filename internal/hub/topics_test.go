@@ -0,0 +1,92 @@
+package hub
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"webrtc-streamer/internal/subtitles"
+)
+
+func TestClientWants(t *testing.T) {
+	h := New(Config{})
+	subtitle := func(lang string, translated bool) hubMessage {
+		return hubMessage{topic: subtitleTopic(lang), lang: lang, translated: translated}
+	}
+	tests := []struct {
+		name string
+		url  string
+		msg  hubMessage
+		want bool
+	}{
+		{"기본 구독 - 원본 자막", "/ws", subtitle("KR", false), true},
+		{"기본 구독 - 번역본 제외", "/ws", subtitle("EN", true), false},
+		{"기본 구독 - admin 제외", "/ws", hubMessage{topic: topicAdmin}, false},
+		{"언어 지정 - 같은 언어", "/ws?languages=en", subtitle("EN", false), true},
+		{"언어 지정 - 번역본", "/ws?languages=en", subtitle("EN", true), true},
+		{"언어 지정 - 다른 언어", "/ws?languages=en", subtitle("KR", false), false},
+		{"하위 토픽만 구독", "/ws?topics=subtitle/kr", subtitle("KR", false), true},
+		{"하위 토픽만 구독 - 다른 언어", "/ws?topics=subtitle/kr", subtitle("EN", false), false},
+		{"상태만 구독", "/ws?topics=status", subtitle("KR", false), false},
+		{"언어 제한은 자막에만", "/ws?languages=en", hubMessage{topic: topicStatus}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := h.newClient(newFakeTransport(), httptest.NewRequest("GET", tt.url, nil))
+			if got := c.wants(tt.msg); got != tt.want {
+				t.Errorf("wants(%s) = %v, want %v", tt.msg.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrivilegedTopics(t *testing.T) {
+	h := New(Config{Authorize: func(r *http.Request) error {
+		if r.URL.Query().Get("token") != "ok" {
+			return errors.New("token required")
+		}
+		return nil
+	}})
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"/ws?topics=admin,presence,status", []string{"status"}},
+		{"/ws?topics=admin,presence,status&token=ok", []string{"admin", "presence", "status"}},
+	}
+	for _, tt := range tests {
+		c := h.newClient(newFakeTransport(), httptest.NewRequest("GET", tt.url, nil))
+		if got := subscriptionsOf(c).Topics; !slices.Equal(got, tt.want) {
+			t.Errorf("%s: topics = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+// hub를 거쳐 언어를 정한 클라이언트에게 그 언어의 자막만 전달된다
+func TestPublishSubtitleLanguageFilter(t *testing.T) {
+	h := New(Config{ClientQueueSize: 8, QueueSize: 8})
+	go h.Run()
+
+	tr := newFakeTransport()
+	c := h.newClient(tr, httptest.NewRequest("GET", "/ws?topics=subtitle&languages=en", nil))
+	h.register <- c
+	go c.writePump()
+	defer func() { h.unregister <- c }()
+
+	h.PublishSubtitle(subtitles.Data{Text: "안녕", LangCode: "KR", IsFinal: true})
+	h.PublishSubtitle(subtitles.Data{Text: "hello", LangCode: "EN", IsFinal: true, TranslatedFrom: "KR"})
+
+	msgs := tr.next(t)
+	if len(msgs) != 1 || msgs[0].Type != TypeSubtitle {
+		t.Fatalf("got %+v, want one subtitle", msgs)
+	}
+	payload, _ := json.Marshal(msgs[0].Payload)
+	var got subtitles.Data
+	json.Unmarshal(payload, &got)
+	if got.Text != "hello" {
+		t.Errorf("got %q, want the English subtitle", got.Text)
+	}
+}
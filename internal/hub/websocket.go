@@ -0,0 +1,676 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ---------- Hub ----------
+
+type Config struct {
+	PartialInterval time.Duration // 부분 자막 전송 간격 (클라이언트별)
+	QueueSize       int           // 발행 대기열
+	ClientQueueSize int           // 클라이언트별 전송 대기열
+	HistorySize     int           // 재접속 시 다시 보낼 최근 메시지 수
+	Compression     bool          // WebSocket permessage-deflate 협상
+
+	// 접속 요청이 운영용 토픽(admin, presence)을 구독하거나 GET /presence를 볼 수 있는지 검사한다 (nil이면 모두 허용)
+	Authorize func(r *http.Request) error
+}
+
+// Run을 별도 고루틴으로 돌려야 클라이언트를 받는다
+func New(cfg Config) *Hub {
+	h := &Hub{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Allow connections from any origin
+			},
+			EnableCompression: cfg.Compression,
+		},
+		clients:         make(map[*Client]bool),
+		register:        make(chan *Client),
+		unregister:      make(chan *Client),
+		subscribe:       make(chan subscriptionChange),
+		stats:           make(chan chan HubStats),
+		shutdown:        make(chan hubShutdown),
+		inWake:          make(chan struct{}, 1),
+		queueSize:       max(cfg.QueueSize, 1),
+		clientQueueSize: max(cfg.ClientQueueSize, 1),
+		historySize:     cfg.HistorySize,
+		authorize:       cfg.Authorize,
+	}
+	h.partialInterval.Store(int64(cfg.PartialInterval))
+	h.presence = newPresence(h)
+	return h
+}
+
+// 부분 자막 최소 전송 간격을 바꾼다 (설정 다시 읽기)
+func (h *Hub) SetPartialInterval(d time.Duration) {
+	h.partialInterval.Store(int64(d))
+}
+
+// 접속자 목록 - WebRTC 시청자도 여기에 등록한다
+func (h *Hub) Presence() *Presence {
+	return h.presence
+}
+
+func (h *Hub) Run() {
+	for {
+		select {
+		case client := <-h.register:
+			if h.closing != "" {
+				client.closeWith(websocket.CloseGoingAway, h.closing)
+				continue
+			}
+			h.clients[client] = true
+			h.connected.Store(int32(len(h.clients)))
+			h.updateLanguages()
+			log.Printf("Client connected (%s). Total clients: %d", client.transport.Name(), len(h.clients))
+			h.presence.Join(client.presenceID, client.transport.Name(), client.name)
+			if client.resumeAfter > 0 {
+				h.replay(client)
+			}
+
+		case client := <-h.unregister:
+			if _, ok := h.clients[client]; ok {
+				h.remove(client)
+				log.Printf("Client disconnected. Total clients: %d", len(h.clients))
+			}
+
+		case ch := <-h.subscribe:
+			h.applySubscription(ch)
+
+		case reply := <-h.stats:
+			reply <- h.collectStats()
+
+		case req := <-h.shutdown:
+			h.closing = req.reason
+			done := make([]chan struct{}, 0, len(h.clients))
+			for client := range h.clients {
+				client.closeWith(websocket.CloseGoingAway, req.reason)
+				h.remove(client)
+				done = append(done, client.done)
+			}
+			log.Printf("Closed all clients: %s", req.reason)
+			req.done <- done
+
+		case <-h.inWake:
+			h.inMu.Lock()
+			batch := h.in
+			h.in = nil
+			h.inMu.Unlock()
+
+			for _, msg := range batch {
+				h.remember(msg)
+				for client := range h.clients {
+					if client.wants(msg) {
+						h.deliver(client, msg)
+					}
+				}
+			}
+		}
+	}
+}
+
+// 모든 클라이언트에게 이유와 함께 닫기 프레임(1001)을 보내고 전송이 끝날 때까지 기다린다.
+// 이후 접속하는 클라이언트는 바로 닫는다.
+func (h *Hub) Shutdown(ctx context.Context, reason string) error {
+	req := hubShutdown{reason: reason, done: make(chan []chan struct{}, 1)}
+	select {
+	case h.shutdown <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for _, done := range <-req.done {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// 발행 대기열에 넣는다. 대기열이 차면 가장 오래된 부분 자막부터 버리며,
+// 버릴 수 없는 메시지(최종 자막, 이벤트)는 한도의 두 배까지 넣고 그 뒤로는 버린다.
+func (h *Hub) send(msg hubMessage) {
+	h.inMu.Lock()
+	if len(h.in) >= h.queueSize {
+		if rest, partial, ok := dropOldestPartial(h.in); ok {
+			h.inDrops.count(partial)
+			h.in = rest
+		} else if msg.droppable() || len(h.in) >= 2*h.queueSize {
+			h.inDrops.count(msg.partialKey != "")
+			h.inMu.Unlock()
+			return
+		}
+	}
+	h.in = append(h.in, msg)
+	h.inMu.Unlock()
+
+	select {
+	case h.inWake <- struct{}{}:
+	default:
+	}
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) remember(msg hubMessage) {
+	if h.historySize <= 0 || msg.droppable() {
+		return
+	}
+	if len(h.history) >= h.historySize {
+		h.history = append(h.history[:0], h.history[1:]...)
+	}
+	h.history = append(h.history, msg)
+}
+
+// 재접속한 클라이언트가 놓친 메시지 중 기록에 남은 것을 다시 보낸다 (hub.run 안에서만 호출된다)
+func (h *Hub) replay(c *Client) {
+	n := 0
+	for _, msg := range h.history {
+		if msg.seq > c.resumeAfter && c.wants(msg) {
+			h.deliver(c, msg)
+			n++
+		}
+	}
+	if n > 0 {
+		log.Printf("Resumed %s client %s after #%d (%d messages)", c.transport.Name(), c.remote, c.resumeAfter, n)
+	}
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) deliver(c *Client, msg hubMessage) {
+	if !c.enqueue(msg, h.clientQueueSize) {
+		log.Printf("%s client %s too slow, disconnecting (dropped %d partials, %d messages)",
+			c.transport.Name(), c.remote, c.drops.Partials, c.drops.Messages)
+		h.remove(c)
+	}
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) remove(c *Client) {
+	delete(h.clients, c)
+	c.close()
+	h.presence.Leave(c.presenceID)
+	h.connected.Store(int32(len(h.clients)))
+	h.updateLanguages()
+}
+
+// 가장 오래된 부분 자막을, 없으면 가장 오래된 버릴 수 있는 메시지를 뺀다 (partial은 뺀 것이 부분 자막인지)
+func dropOldestPartial(queue []hubMessage) (rest []hubMessage, partial, ok bool) {
+	i := dropIndex(len(queue),
+		func(i int) bool { return queue[i].partialKey != "" },
+		func(i int) bool { return queue[i].droppable() })
+	if i < 0 {
+		return queue, false, false
+	}
+	partial = queue[i].partialKey != ""
+	return append(queue[:i], queue[i+1:]...), partial, true
+}
+
+// 앞에서부터 먼저 prefer, 없으면 ok를 만족하는 가장 오래된 항목
+func dropIndex(n int, prefer, ok func(i int) bool) int {
+	for i := 0; i < n; i++ {
+		if prefer(i) {
+			return i
+		}
+	}
+	for i := 0; i < n; i++ {
+		if ok(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *MessageDrops) count(partial bool) {
+	if partial {
+		d.Partials++
+	} else {
+		d.Messages++
+	}
+}
+
+// ?topics=subtitle/kr,status&languages=ko,en 으로 처음 구독할 토픽과 자막 언어를, ?name= 으로 표시 이름을 지정할 수 있다
+func (h *Hub) newClient(t clientTransport, r *http.Request) *Client {
+	privileged := h.authorize == nil || h.authorize(r) == nil
+	topics := defaultTopics
+	if q := r.URL.Query().Get("topics"); q != "" {
+		topics, _ = parseTopics(strings.Split(q, ","))
+		topics, _ = allowedTopics(topics, privileged)
+	}
+	languages, _ := parseLanguages(strings.Split(r.URL.Query().Get("languages"), ","))
+
+	client := &Client{
+		hub:         h,
+		transport:   t,
+		remote:      r.RemoteAddr,
+		presenceID:  h.presence.NewID(),
+		name:        r.URL.Query().Get("name"),
+		privileged:  privileged,
+		topics:      make(map[string]bool),
+		languages:   make(map[string]bool),
+		partials:    make(map[string][]byte),
+		wake:        make(chan struct{}, 1),
+		partialWake: make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	for _, t := range topics {
+		if len(client.topics) < maxClientTopics {
+			client.topics[t] = true
+		}
+	}
+	for _, l := range languages {
+		client.languages[l] = true
+	}
+	return client
+}
+
+// GET /ws
+func (h *Hub) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	// ?encoding=cbor 이면 봉투를 CBOR 바이너리 프레임으로 보낸다
+	t := &wsTransport{conn: conn, binary: r.URL.Query().Get("encoding") == "cbor"}
+	client := h.newClient(t, r)
+	client.binary = t.binary
+	h.register <- client
+
+	go client.writePump()
+	go t.readPump(client)
+}
+
+type wsTransport struct {
+	conn   *websocket.Conn
+	binary bool
+}
+
+func (t *wsTransport) Name() string { return "websocket" }
+
+// 텍스트는 줄바꿈으로, CBOR는 그대로 이어 붙여(CBOR sequence) 한 프레임에 보낸다
+func (t *wsTransport) WriteBatch(messages [][]byte) error {
+	frame := websocket.TextMessage
+	if t.binary {
+		frame = websocket.BinaryMessage
+	}
+	t.conn.SetWriteDeadline(time.Now().Add(5 * time.Second)) // 더 짧은 타임아웃
+	w, err := t.conn.NextWriter(frame)
+	if err != nil {
+		return err
+	}
+	for i, message := range messages {
+		if i > 0 && !t.binary {
+			w.Write([]byte{'\n'})
+		}
+		w.Write(message)
+	}
+	return w.Close()
+}
+
+func (t *wsTransport) Ping() error {
+	t.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return t.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
+// 느린 클라이언트는 닫기 코드 1013, 서버 종료는 1001로 끊는다
+func (t *wsTransport) Close(code int, reason string) {
+	if code == 0 {
+		code = websocket.CloseNormalClosure
+	}
+	t.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	t.conn.Close()
+}
+
+func (t *wsTransport) readPump(c *Client) {
+	defer func() {
+		c.hub.unregister <- c
+		t.conn.Close()
+		log.Printf("WebSocket client disconnected")
+	}()
+
+	t.conn.SetReadLimit(4096)
+	t.conn.SetReadDeadline(time.Now().Add(30 * time.Second)) // 더 짧은 타임아웃
+	t.conn.SetPongHandler(func(string) error {
+		t.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		return nil
+	})
+
+	// 연결 끊김을 즉시 감지하기 위한 클로즈 핸들러
+	t.conn.SetCloseHandler(func(code int, text string) error {
+		log.Printf("WebSocket close: code=%d, text=%s", code, text)
+		return nil
+	})
+
+	for {
+		_, data, err := t.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseNormalClosure) {
+				log.Printf("WebSocket unexpected error: %v", err)
+			}
+			break
+		}
+		c.handleMessage(data)
+	}
+}
+
+// ---------- Client send queue ----------
+
+// 클라이언트 인코딩에 맞는 메시지. CBOR는 처음 필요할 때 한 번만 만들어 다른 클라이언트와 나눠 쓴다.
+func (c *Client) encode(msg hubMessage) ([]byte, error) {
+	if !c.binary || msg.cbor == nil {
+		return msg.data, nil
+	}
+	if msg.cbor.data == nil && msg.cbor.err == nil {
+		msg.cbor.data, msg.cbor.err = jsonToCBOR(msg.data)
+		if msg.cbor.err != nil {
+			log.Printf("CBOR encoding of message #%d failed: %v", msg.seq, msg.cbor.err)
+		}
+	}
+	return msg.cbor.data, msg.cbor.err
+}
+
+func wakeup(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// 전송 대기열에 넣는다 (대기 중인 부분 자막도 한도에 포함).
+// 한도에 닿으면 가장 오래된 부분 자막, 그다음 오래된 상태 메시지 순으로 버리고 클라이언트에게 알린다.
+// 버릴 수 없는 메시지가 한도의 두 배까지 쌓이면 false를 반환하고, hub는 클라이언트를 끊는다.
+// 클라이언트 인코딩으로 바꿀 수 없는 메시지는 버린 것으로 센다.
+func (c *Client) enqueue(msg hubMessage, limit int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return true
+	}
+	defer wakeup(c.wake)
+
+	data, err := c.encode(msg)
+	if err != nil {
+		if msg.finalKey != "" {
+			c.dropPartialLocked(msg.finalKey)
+		}
+		c.dropLocked(msg.partialKey != "")
+		return true
+	}
+
+	if msg.partialKey != "" {
+		if _, ok := c.partials[msg.partialKey]; !ok {
+			if c.pendingLocked() >= limit && !c.makeRoomLocked() {
+				c.dropLocked(true)
+				return true
+			}
+			c.partialIDs = append(c.partialIDs, msg.partialKey)
+		}
+		c.partials[msg.partialKey] = data
+		wakeup(c.partialWake)
+		return true
+	}
+
+	// 최종 자막이 대기 중인 부분 자막을 대체
+	if msg.finalKey != "" {
+		c.dropPartialLocked(msg.finalKey)
+	}
+	if c.pendingLocked() >= limit && !c.makeRoomLocked() {
+		if msg.droppable() {
+			c.dropLocked(false)
+			return true
+		}
+		if c.pendingLocked() >= 2*limit {
+			c.closed = true
+			c.closeCode, c.closeReason = websocket.CloseTryAgainLater, "client too slow"
+			return false
+		}
+	}
+	c.queue = append(c.queue, clientMessage{data: data, droppable: msg.droppable()})
+	return true
+}
+
+func (c *Client) pendingLocked() int {
+	return len(c.queue) + len(c.partialIDs)
+}
+
+func (c *Client) dropLocked(partial bool) {
+	c.drops.count(partial)
+	c.dropNotice = true
+}
+
+// 가장 오래된 부분 자막, 없으면 가장 오래된 버릴 수 있는 메시지를 버린다
+func (c *Client) makeRoomLocked() bool {
+	if len(c.partialIDs) > 0 {
+		delete(c.partials, c.partialIDs[0])
+		c.partialIDs = c.partialIDs[1:]
+		c.dropLocked(true)
+		return true
+	}
+	i := dropIndex(len(c.queue), func(int) bool { return false }, func(i int) bool { return c.queue[i].droppable })
+	if i < 0 {
+		return false
+	}
+	c.queue = append(c.queue[:i], c.queue[i+1:]...)
+	c.dropLocked(false)
+	return true
+}
+
+func (c *Client) dropPartialLocked(key string) {
+	if _, ok := c.partials[key]; !ok {
+		return
+	}
+	delete(c.partials, key)
+	for i, id := range c.partialIDs {
+		if id == key {
+			c.partialIDs = append(c.partialIDs[:i], c.partialIDs[i+1:]...)
+			break
+		}
+	}
+}
+
+// hub에서 빠진 클라이언트의 writePump를 끝낸다
+func (c *Client) close() {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	wakeup(c.wake)
+}
+
+// 다음 close()에서 끊는 이유를 알리고 code로 닫도록 한다
+func (c *Client) closeWith(code int, reason string) {
+	c.mu.Lock()
+	c.closeCode, c.closeReason = code, reason
+	c.closed = true
+	c.mu.Unlock()
+	wakeup(c.wake)
+}
+
+// 보낼 메시지와, 버린 메시지가 있으면 그 알림을 꺼낸다
+func (c *Client) take() (messages [][]byte, notice *ClientNotice, closed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range c.queue {
+		messages = append(messages, m.data)
+	}
+	c.queue = nil
+	if c.dropNotice || c.closeReason != "" {
+		notice = &ClientNotice{Event: "dropped", Dropped: c.drops}
+		if c.closeReason != "" {
+			notice.Event, notice.Reason = "disconnect", c.closeReason
+		}
+		c.dropNotice = false
+	}
+	return messages, notice, c.closed
+}
+
+// 대기 중인 부분 자막을 발화 순서대로 전송
+func (c *Client) flushPartials() error {
+	c.mu.Lock()
+	pending := make([][]byte, 0, len(c.partialIDs))
+	for _, id := range c.partialIDs {
+		pending = append(pending, c.partials[id])
+	}
+	c.partials = make(map[string][]byte)
+	c.partialIDs = nil
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return c.transport.WriteBatch(pending)
+}
+
+// 대기열의 메시지와 알림을 한 번에 보낸다
+func (c *Client) writeBatch(messages [][]byte, notice *ClientNotice) error {
+	if notice != nil {
+		if msg, err := c.hub.message(wsTypeNotice, notice); err == nil {
+			if data, err := c.encode(msg); err == nil {
+				messages = append(messages, data)
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return c.transport.WriteBatch(messages)
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(25 * time.Second) // 더 빈번한 핑
+	var partialTimer *time.Timer
+	var partialDue <-chan time.Time
+	var lastPartial time.Time
+	defer func() {
+		ticker.Stop()
+		close(c.done)
+		if partialTimer != nil {
+			partialTimer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-c.partialWake:
+			// 부분 자막은 partialInterval마다 최대 한 번만 전송
+			if wait := time.Duration(c.hub.partialInterval.Load()) - time.Since(lastPartial); wait > 0 {
+				if partialDue == nil {
+					partialTimer = time.NewTimer(wait)
+					partialDue = partialTimer.C
+				}
+				continue
+			}
+			if err := c.flushPartials(); err != nil {
+				c.transport.Close(0, "")
+				return
+			}
+			lastPartial = time.Now()
+
+		case <-partialDue:
+			partialDue = nil
+			if err := c.flushPartials(); err != nil {
+				c.transport.Close(0, "")
+				return
+			}
+			lastPartial = time.Now()
+
+		case <-c.wake:
+			messages, notice, closed := c.take()
+			if closed {
+				// 서버가 끊는 경우 (느린 클라이언트, 서버 종료) 이유를 알린 뒤 닫는다
+				if notice != nil && notice.Event == "disconnect" {
+					c.writeBatch(nil, notice)
+				}
+				c.mu.Lock()
+				code, reason := c.closeCode, c.closeReason
+				c.mu.Unlock()
+				c.transport.Close(code, reason)
+				return
+			}
+			if err := c.writeBatch(messages, notice); err != nil {
+				c.transport.Close(0, "")
+				return
+			}
+
+		case <-ticker.C:
+			if err := c.transport.Ping(); err != nil {
+				c.transport.Close(0, "")
+				return
+			}
+		}
+	}
+}
+
+// ---------- Hub stats ----------
+
+type HubStats struct {
+	Clients int           `json:"clients"`
+	Queued  int           `json:"queued"`  // 발행 대기열
+	Dropped MessageDrops  `json:"dropped"` // 발행 대기열에서 버린 수
+	List    []ClientStats `json:"client_list"`
+}
+
+type ClientStats struct {
+	Transport string       `json:"transport"` // websocket | sse
+	Encoding  string       `json:"encoding"`  // json | cbor
+	Remote    string       `json:"remote"`
+	Topics    []string     `json:"topics"`
+	Languages []string     `json:"languages,omitempty"`
+	Queued    int          `json:"queued"`
+	Dropped   MessageDrops `json:"dropped"`
+}
+
+func (c *Client) encoding() string {
+	if c.binary {
+		return "cbor"
+	}
+	return "json"
+}
+
+// hub.run 안에서만 호출된다
+func (h *Hub) collectStats() HubStats {
+	h.inMu.Lock()
+	stats := HubStats{Clients: len(h.clients), Queued: len(h.in), Dropped: h.inDrops}
+	h.inMu.Unlock()
+
+	stats.List = make([]ClientStats, 0, len(h.clients))
+	for c := range h.clients {
+		info := subscriptionsOf(c)
+		c.mu.Lock()
+		stats.List = append(stats.List, ClientStats{
+			Transport: c.transport.Name(),
+			Encoding:  c.encoding(),
+			Remote:    c.remote,
+			Topics:    info.Topics,
+			Languages: info.Languages,
+			Queued:    c.pendingLocked(),
+			Dropped:   c.drops,
+		})
+		c.mu.Unlock()
+	}
+	return stats
+}
+
+// GET /ws/stats - 접속한 클라이언트와 대기열, 버린 메시지 수
+func (h *Hub) ServeStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	reply := make(chan HubStats, 1)
+	h.stats <- reply
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(<-reply)
+}
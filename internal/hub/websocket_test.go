@@ -0,0 +1,176 @@
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 보낸 메시지를 채널로 넘기는 가짜 전송
+type fakeTransport struct {
+	batches chan [][]byte
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{batches: make(chan [][]byte, 64)}
+}
+
+func (t *fakeTransport) Name() string { return "fake" }
+
+func (t *fakeTransport) WriteBatch(messages [][]byte) error {
+	t.batches <- slices.Clone(messages)
+	return nil
+}
+
+func (t *fakeTransport) Ping() error       { return nil }
+func (t *fakeTransport) Close(int, string) {}
+
+func (t *fakeTransport) next(tb testing.TB) []WSMessage {
+	tb.Helper()
+	select {
+	case batch := <-t.batches:
+		msgs := make([]WSMessage, len(batch))
+		for i, b := range batch {
+			if err := json.Unmarshal(b, &msgs[i]); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		return msgs
+	case <-time.After(2 * time.Second):
+		tb.Fatal("no message")
+		return nil
+	}
+}
+
+func TestDropOldestPartial(t *testing.T) {
+	final := hubMessage{topic: topicSubtitle, finalKey: "u1", data: []byte("final")}
+	partial1 := hubMessage{topic: topicSubtitle, partialKey: "u2", data: []byte("p1")}
+	partial2 := hubMessage{topic: topicSubtitle, partialKey: "u3", data: []byte("p2")}
+	status := hubMessage{topic: topicStatus, data: []byte("status")}
+	event := hubMessage{topic: topicAdmin, data: []byte("event")}
+
+	tests := []struct {
+		name        string
+		queue       []hubMessage
+		want        []string
+		wantPartial bool
+		wantOK      bool
+	}{
+		{"빈 대기열", nil, nil, false, false},
+		{"가장 오래된 부분 자막", []hubMessage{final, status, partial1, partial2}, []string{"final", "status", "p2"}, true, true},
+		{"부분 자막이 없으면 상태", []hubMessage{final, status, event}, []string{"final", "event"}, false, true},
+		{"버릴 수 없는 메시지만", []hubMessage{final, event}, []string{"final", "event"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, partial, ok := dropOldestPartial(slices.Clone(tt.queue))
+			var got []string
+			for _, m := range rest {
+				got = append(got, string(m.data))
+			}
+			if !slices.Equal(got, tt.want) || partial != tt.wantPartial || ok != tt.wantOK {
+				t.Errorf("got %v partial=%v ok=%v, want %v partial=%v ok=%v",
+					got, partial, ok, tt.want, tt.wantPartial, tt.wantOK)
+			}
+		})
+	}
+}
+
+// 발행 대기열이 차면 부분 자막을 버리고, 버릴 수 없는 메시지는 한도의 두 배까지 넣는다
+func TestSendDropsOldestPartialWhenFull(t *testing.T) {
+	h := New(Config{QueueSize: 2})
+	h.send(hubMessage{topic: topicSubtitle, partialKey: "u1", data: []byte("p1")})
+	h.send(hubMessage{topic: topicSubtitle, finalKey: "u0", data: []byte("f0")})
+	h.send(hubMessage{topic: topicSubtitle, partialKey: "u2", data: []byte("p2")})
+	h.send(hubMessage{topic: topicAdmin, data: []byte("event")})
+	h.send(hubMessage{topic: topicStatus, data: []byte("status")})
+
+	var got []string
+	for _, m := range h.in {
+		got = append(got, string(m.data))
+	}
+	if want := []string{"f0", "event"}; !slices.Equal(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+	if h.inDrops.Partials != 2 || h.inDrops.Messages != 1 {
+		t.Errorf("drops = %+v, want 2 partials, 1 message", h.inDrops)
+	}
+}
+
+// Run이 대기열을 비우지 못해도 최종 자막은 한도의 두 배를 넘어 쌓이지 않는다
+func TestSendCapsFinals(t *testing.T) {
+	const size = 4
+	h := New(Config{QueueSize: size})
+	for i := 0; i < 3*size; i++ {
+		h.send(hubMessage{topic: topicSubtitle, finalKey: fmt.Sprint(i), data: []byte(fmt.Sprint(i))})
+	}
+	if len(h.in) != 2*size {
+		t.Fatalf("queue length = %d, want %d", len(h.in), 2*size)
+	}
+	// 먼저 들어온 메시지를 남기고 넘친 것을 버린다
+	if first, last := string(h.in[0].data), string(h.in[len(h.in)-1].data); first != "0" || last != "7" {
+		t.Errorf("queue = %s..%s, want 0..7", first, last)
+	}
+	if h.inDrops != (MessageDrops{Messages: size}) {
+		t.Errorf("drops = %+v, want %d messages", h.inDrops, size)
+	}
+}
+
+// 같은 발화의 부분 자막은 최신 것만 남고, 최종 자막이 오면 대기 중인 부분 자막을 대체한다
+func TestEnqueueCoalescesPartials(t *testing.T) {
+	h := New(Config{})
+	c := h.newClient(newFakeTransport(), httptest.NewRequest("GET", "/ws", nil))
+	partial := func(id, text string) hubMessage {
+		return hubMessage{topic: topicSubtitle, partialKey: id, data: []byte(text)}
+	}
+
+	c.enqueue(partial("u1", "p1-1"), 8)
+	c.enqueue(partial("u2", "p2-1"), 8)
+	c.enqueue(partial("u1", "p1-2"), 8)
+	if !slices.Equal(c.partialIDs, []string{"u1", "u2"}) || string(c.partials["u1"]) != "p1-2" {
+		t.Fatalf("partials = %v %q, want [u1 u2] with latest u1", c.partialIDs, c.partials["u1"])
+	}
+
+	c.enqueue(hubMessage{topic: topicSubtitle, finalKey: "u1", data: []byte("f1")}, 8)
+	if !slices.Equal(c.partialIDs, []string{"u2"}) {
+		t.Errorf("partials after final = %v, want [u2]", c.partialIDs)
+	}
+	messages, notice, _ := c.take()
+	if len(messages) != 1 || string(messages[0]) != "f1" || notice != nil {
+		t.Errorf("take() = %q, %v; want [f1] without notice", messages, notice)
+	}
+}
+
+// 한도에 닿으면 부분 자막과 상태부터 버리고, 버릴 수 없는 메시지가 두 배까지 쌓이면 끊는다
+func TestEnqueueSlowClient(t *testing.T) {
+	h := New(Config{})
+	c := h.newClient(newFakeTransport(), httptest.NewRequest("GET", "/ws", nil))
+	const limit = 2
+	event := hubMessage{topic: topicAdmin, data: []byte("event")}
+
+	c.enqueue(hubMessage{topic: topicSubtitle, partialKey: "u1"}, limit)
+	c.enqueue(hubMessage{topic: topicStatus, data: []byte("status")}, limit)
+	c.enqueue(event, limit) // 부분 자막을 버린다
+	c.enqueue(event, limit) // 상태를 버린다
+	if c.drops != (MessageDrops{Partials: 1, Messages: 1}) {
+		t.Errorf("drops = %+v, want 1 partial, 1 message", c.drops)
+	}
+	c.enqueue(hubMessage{topic: topicStatus}, limit) // 새 상태는 바로 버린다
+	if c.drops.Messages != 2 {
+		t.Errorf("dropped messages = %d, want 2", c.drops.Messages)
+	}
+
+	if !c.enqueue(event, limit) || !c.enqueue(event, limit) {
+		t.Fatal("client disconnected before twice the limit")
+	}
+	if c.enqueue(event, limit) {
+		t.Fatal("client kept after twice the limit")
+	}
+	_, notice, closed := c.take()
+	if !closed || notice == nil || notice.Event != "disconnect" || notice.Reason != "client too slow" {
+		t.Errorf("take() notice = %+v closed = %v, want disconnect", notice, closed)
+	}
+}
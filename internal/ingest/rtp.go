@@ -0,0 +1,167 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"syscall"
+
+	"github.com/pion/rtp"
+)
+
+// ---------- UDP(RTP) ----------
+
+func listenUDP(label, bindIP string, port int) (*net.UDPConn, error) {
+	addr := &net.UDPAddr{IP: net.ParseIP(bindIP), Port: port}
+
+	lc := &net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			var err error
+			c.Control(func(fd uintptr) {
+				err = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+			})
+			return err
+		},
+	}
+
+	conn, err := lc.ListenPacket(context.Background(), "udp", addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s UDP port %d in use", label, addr.Port)
+	}
+
+	udpConn := conn.(*net.UDPConn)
+	bufSize := 2 * 1024 * 1024
+	udpConn.SetReadBuffer(bufSize)
+	udpConn.SetWriteBuffer(bufSize)
+
+	log.Printf("%s UDP listener ready on %s", label, addr)
+	return udpConn, nil
+}
+
+// ---------- RTP ingest ----------
+
+// 수신한 패킷은 구독자 사이에서 공유되므로 sink는 패킷을 수정하면 안 된다
+type Sink func(pkt *rtp.Packet)
+
+// 녹화, DVR, HLS 등 RTP 소비자가 의존하는 구독 인터페이스.
+// 테스트에서는 패킷을 직접 흘려보내는 가짜 구현으로 대체할 수 있다.
+type Stream interface {
+	Subscribe(sink Sink) (func(), error)
+}
+
+// GStreamer가 보내는 RTP 스트림 하나를 시청자 유무와 관계없이 계속 수신하고
+// WebRTC 세션, 녹화 등 구독자에게 나눠준다.
+type RTPSource struct {
+	label  string
+	bindIP string
+	port   int
+
+	mu     sync.RWMutex
+	conn   *net.UDPConn
+	nextID uint64
+	sinks  map[uint64]Sink
+	list   []Sink // 읽기 루프용 복사본 (copy-on-write)
+}
+
+func NewRTPSource(label, bindIP string, port int) *RTPSource {
+	return &RTPSource{
+		label:  label,
+		bindIP: bindIP,
+		port:   port,
+		sinks:  make(map[uint64]Sink),
+	}
+}
+
+// 리스너가 아직 없으면 연다
+func (s *RTPSource) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return nil
+	}
+
+	conn, err := listenUDP(s.label, s.bindIP, s.port)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	go s.readLoop(conn)
+	return nil
+}
+
+func (s *RTPSource) Close() {
+	s.mu.Lock()
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// sink를 등록하고 구독 해제 함수를 반환
+func (s *RTPSource) Subscribe(sink Sink) (func(), error) {
+	if err := s.Start(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.sinks[id] = sink
+	s.rebuildLocked()
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.sinks, id)
+			s.rebuildLocked()
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+func (s *RTPSource) rebuildLocked() {
+	list := make([]Sink, 0, len(s.sinks))
+	for _, sink := range s.sinks {
+		list = append(list, sink)
+	}
+	s.list = list
+}
+
+func (s *RTPSource) readLoop(conn *net.UDPConn) {
+	defer func() {
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+		conn.Close()
+		log.Printf("%s UDP listener closed", s.label)
+	}()
+
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return // 연결 종료
+		}
+
+		// 구독자가 패킷을 보관할 수 있도록 패킷마다 새 버퍼 사용
+		var pkt rtp.Packet
+		if pkt.Unmarshal(append([]byte(nil), buf[:n]...)) != nil {
+			continue
+		}
+
+		s.mu.RLock()
+		sinks := s.list
+		s.mu.RUnlock()
+		for _, sink := range sinks {
+			sink(&pkt)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package media
+
+import (
+	"errors"
+
+	"github.com/pion/rtp"
+)
+
+// ---------- H.264 ----------
+
+const (
+	naluTypeIDR   = 5
+	naluTypeSPS   = 7
+	naluTypePPS   = 8
+	naluTypeAUD   = 9
+	naluTypeSTAPA = 24
+	naluTypeFUA   = 28
+)
+
+// 같은 RTP 타임스탬프를 가진 NAL 묶음 (한 프레임)
+type H264AccessUnit struct {
+	Timestamp uint32
+	NALUs     [][]byte
+}
+
+func (au *H264AccessUnit) IsKey() bool {
+	for _, n := range au.NALUs {
+		if len(n) > 0 && n[0]&0x1F == naluTypeIDR {
+			return true
+		}
+	}
+	return false
+}
+
+// 키프레임에 포함된 SPS/PPS
+func (au *H264AccessUnit) ParameterSets() (sps, pps []byte) {
+	for _, n := range au.NALUs {
+		if len(n) == 0 {
+			continue
+		}
+		switch n[0] & 0x1F {
+		case naluTypeSPS:
+			sps = n
+		case naluTypePPS:
+			pps = n
+		}
+	}
+	return sps, pps
+}
+
+// 4바이트 길이 접두사 형식 (MP4 샘플). SPS/PPS/AUD는 샘플 설명에 있으므로 제외
+func (au *H264AccessUnit) AVCC() []byte {
+	size := 0
+	for _, n := range au.NALUs {
+		size += 4 + len(n)
+	}
+	out := make([]byte, 0, size)
+	for _, n := range au.NALUs {
+		if len(n) == 0 {
+			continue
+		}
+		switch n[0] & 0x1F {
+		case naluTypeSPS, naluTypePPS, naluTypeAUD:
+			continue
+		}
+		l := len(n)
+		out = append(out, byte(l>>24), byte(l>>16), byte(l>>8), byte(l))
+		out = append(out, n...)
+	}
+	return out
+}
+
+// 4바이트 길이 접두사 형식의 샘플을 NAL 단위로 나눈다 (AVCC의 역)
+func AVCCAccessUnit(data []byte, ts uint32) *H264AccessUnit {
+	au := &H264AccessUnit{Timestamp: ts}
+	for len(data) >= 4 {
+		n := int(data[0])<<24 | int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		if n <= 0 || 4+n > len(data) {
+			break
+		}
+		au.NALUs = append(au.NALUs, data[4:4+n])
+		data = data[4+n:]
+	}
+	return au
+}
+
+// 시작 코드 형식 (Annex-B elementary stream)
+func (au *H264AccessUnit) AnnexB() []byte {
+	var out []byte
+	for _, n := range au.NALUs {
+		out = append(out, 0, 0, 0, 1)
+		out = append(out, n...)
+	}
+	return out
+}
+
+// RTP(RFC 6184) 패킷을 접근 단위(프레임)로 조립한다.
+// 패킷 손실이 생기면 다음 키프레임까지 프레임을 버린다.
+type H264Depacketizer struct {
+	started bool
+	lastSeq uint16
+	broken  bool
+
+	// 손실이 난 프레임의 나머지 패킷은 버린다
+	dropping      bool
+	dropTimestamp uint32
+
+	au    *H264AccessUnit
+	fuBuf []byte
+}
+
+// 완성된 접근 단위가 있으면 반환 (마커 비트 또는 타임스탬프 변경 시)
+func (d *H264Depacketizer) Push(pkt *rtp.Packet) *H264AccessUnit {
+	var done *H264AccessUnit
+
+	// 첫 패킷이나 손실 직후에는 키프레임부터 시작
+	if !d.started {
+		d.broken = true
+	} else if pkt.SequenceNumber != d.lastSeq+1 {
+		d.broken = true
+		d.au = nil
+		d.fuBuf = nil
+		d.dropping = true
+		d.dropTimestamp = pkt.Timestamp
+	}
+	d.started = true
+	d.lastSeq = pkt.SequenceNumber
+
+	if d.dropping {
+		if pkt.Timestamp == d.dropTimestamp {
+			return nil
+		}
+		d.dropping = false
+	}
+
+	if d.au != nil && d.au.Timestamp != pkt.Timestamp {
+		done = d.finish()
+	}
+	if d.au == nil {
+		d.au = &H264AccessUnit{Timestamp: pkt.Timestamp}
+	}
+
+	d.parsePayload(pkt.Payload)
+
+	if pkt.Marker {
+		if au := d.finish(); au != nil {
+			done = au
+		}
+	}
+	return done
+}
+
+func (d *H264Depacketizer) finish() *H264AccessUnit {
+	au := d.au
+	d.au = nil
+	d.fuBuf = nil
+	if au == nil || len(au.NALUs) == 0 {
+		return nil
+	}
+	if d.broken {
+		if !au.IsKey() {
+			return nil
+		}
+		d.broken = false
+	}
+	return au
+}
+
+func (d *H264Depacketizer) parsePayload(payload []byte) {
+	if len(payload) < 1 {
+		return
+	}
+
+	switch payload[0] & 0x1F {
+	case naluTypeSTAPA:
+		buf := payload[1:]
+		for len(buf) > 2 {
+			size := int(buf[0])<<8 | int(buf[1])
+			if size == 0 || 2+size > len(buf) {
+				return
+			}
+			d.au.NALUs = append(d.au.NALUs, buf[2:2+size])
+			buf = buf[2+size:]
+		}
+
+	case naluTypeFUA:
+		if len(payload) < 2 {
+			return
+		}
+		indicator, header := payload[0], payload[1]
+		if header&0x80 != 0 { // start
+			d.fuBuf = append([]byte{indicator&0xE0 | header&0x1F}, payload[2:]...)
+		} else if d.fuBuf != nil {
+			d.fuBuf = append(d.fuBuf, payload[2:]...)
+		}
+		if header&0x40 != 0 && d.fuBuf != nil { // end
+			d.au.NALUs = append(d.au.NALUs, d.fuBuf)
+			d.fuBuf = nil
+		}
+
+	default:
+		d.au.NALUs = append(d.au.NALUs, payload)
+	}
+}
+
+// 패킷이 키프레임(SPS 또는 IDR)의 시작을 담고 있는지 - 조립 없이 패킷 단위로 판단
+func RTPStartsKeyframe(payload []byte) bool {
+	if len(payload) < 1 {
+		return false
+	}
+	switch payload[0] & 0x1F {
+	case naluTypeSPS, naluTypeIDR:
+		return true
+	case naluTypeSTAPA:
+		buf := payload[1:]
+		for len(buf) > 2 {
+			size := int(buf[0])<<8 | int(buf[1])
+			if size == 0 || 2+size > len(buf) {
+				return false
+			}
+			if t := buf[2] & 0x1F; t == naluTypeSPS || t == naluTypeIDR {
+				return true
+			}
+			buf = buf[2+size:]
+		}
+	case naluTypeFUA:
+		return len(payload) >= 2 && payload[1]&0x80 != 0 && payload[1]&0x1F == naluTypeIDR
+	}
+	return false
+}
+
+// ---------- SPS ----------
+
+type bitReader struct {
+	data []byte
+	pos  int // bit 단위
+}
+
+var errBitstreamEnd = errors.New("h264: unexpected end of bitstream")
+
+func (r *bitReader) bit() (uint32, error) {
+	if r.pos >= len(r.data)*8 {
+		return 0, errBitstreamEnd
+	}
+	b := r.data[r.pos/8] >> (7 - uint(r.pos%8)) & 1
+	r.pos++
+	return uint32(b), nil
+}
+
+func (r *bitReader) bits(n int) (uint32, error) {
+	var v uint32
+	for i := 0; i < n; i++ {
+		b, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<1 | b
+	}
+	return v, nil
+}
+
+// Exp-Golomb 부호 없는 값
+func (r *bitReader) ue() (uint32, error) {
+	zeros := 0
+	for {
+		b, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		if b == 1 {
+			break
+		}
+		zeros++
+		if zeros > 31 {
+			return 0, errors.New("h264: invalid exp-golomb code")
+		}
+	}
+	v, err := r.bits(zeros)
+	return (1<<zeros - 1) + v, err
+}
+
+func (r *bitReader) se() (int32, error) {
+	v, err := r.ue()
+	if v%2 == 0 {
+		return -int32(v / 2), err
+	}
+	return int32(v/2) + 1, err
+}
+
+// emulation prevention 바이트(00 00 03) 제거
+func unescapeRBSP(nalu []byte) []byte {
+	out := make([]byte, 0, len(nalu))
+	zeros := 0
+	for _, b := range nalu {
+		if zeros >= 2 && b == 3 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// SPS에서 크롭이 반영된 영상 크기를 읽는다
+func ParseSPSResolution(sps []byte) (width, height int, err error) {
+	if len(sps) < 4 {
+		return 0, 0, errors.New("h264: SPS too short")
+	}
+	r := &bitReader{data: unescapeRBSP(sps[1:])}
+	profile, _ := r.bits(8)
+	r.bits(16) // constraint flags, level
+
+	// seq_parameter_set_id
+	if _, err := r.ue(); err != nil {
+		return 0, 0, err
+	}
+
+	chromaFormat := uint32(1)
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		if chromaFormat, err = r.ue(); err != nil {
+			return 0, 0, err
+		}
+		if chromaFormat == 3 {
+			r.bit() // separate_colour_plane_flag
+		}
+		r.ue()  // bit_depth_luma_minus8
+		r.ue()  // bit_depth_chroma_minus8
+		r.bit() // qpprime_y_zero_transform_bypass_flag
+		if present, _ := r.bit(); present == 1 {
+			lists := 8
+			if chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if listPresent, _ := r.bit(); listPresent == 1 {
+					size := 16
+					if i >= 6 {
+						size = 64
+					}
+					last, next := int32(8), int32(8)
+					for j := 0; j < size; j++ {
+						if next != 0 {
+							delta, err := r.se()
+							if err != nil {
+								return 0, 0, err
+							}
+							next = (last + delta + 256) % 256
+						}
+						if next != 0 {
+							last = next
+						}
+					}
+				}
+			}
+		}
+	}
+
+	r.ue() // log2_max_frame_num_minus4
+	pocType, err := r.ue()
+	if err != nil {
+		return 0, 0, err
+	}
+	switch pocType {
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.bit() // delta_pic_order_always_zero_flag
+		r.se()  // offset_for_non_ref_pic
+		r.se()  // offset_for_top_to_bottom_field
+		n, err := r.ue()
+		if err != nil {
+			return 0, 0, err
+		}
+		for i := uint32(0); i < n; i++ {
+			r.se()
+		}
+	}
+	r.ue()  // max_num_ref_frames
+	r.bit() // gaps_in_frame_num_value_allowed_flag
+
+	widthMbs, _ := r.ue()
+	heightMapUnits, _ := r.ue()
+	frameMbsOnly, err := r.bit()
+	if err != nil {
+		return 0, 0, err
+	}
+	if frameMbsOnly == 0 {
+		r.bit() // mb_adaptive_frame_field_flag
+	}
+	r.bit() // direct_8x8_inference_flag
+
+	width = int(widthMbs+1) * 16
+	height = int(2-frameMbsOnly) * int(heightMapUnits+1) * 16
+
+	cropping, err := r.bit()
+	if err != nil {
+		return 0, 0, err
+	}
+	if cropping == 1 {
+		left, _ := r.ue()
+		right, _ := r.ue()
+		top, _ := r.ue()
+		bottom, err := r.ue()
+		if err != nil {
+			return 0, 0, err
+		}
+		cropX, cropY := 1, int(2-frameMbsOnly)
+		if chromaFormat == 1 || chromaFormat == 2 {
+			cropX = 2
+		}
+		if chromaFormat == 1 {
+			cropY *= 2
+		}
+		width -= cropX * int(left+right)
+		height -= cropY * int(top+bottom)
+	}
+
+	if width <= 0 || height <= 0 {
+		return 0, 0, errors.New("h264: invalid SPS dimensions")
+	}
+	return width, height, nil
+}
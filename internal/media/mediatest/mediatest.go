@@ -0,0 +1,125 @@
+// Package mediatest는 RTP 소비자(HLS, 녹화, 시간 이동, 스냅샷) 테스트에 쓰는
+// 가짜 수신과 합성 H.264/Opus 패킷을 제공한다. 영상 내용은 디코딩할 수 없다.
+package mediatest
+
+import (
+	"sync"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/ingest"
+)
+
+// 320x240 Baseline SPS와 PPS
+var (
+	SPS = []byte{0x67, 0x42, 0xc0, 0x1e, 0xda, 0x05, 0x07, 0xe4}
+	PPS = []byte{0x68, 0xce, 0x3c, 0x80}
+)
+
+const (
+	Width  = 320
+	Height = 240
+
+	// 30fps, 20ms Opus
+	FrameTicks = 3000
+	AudioTicks = 960
+)
+
+// 패킷을 Send로 직접 흘려보내는 ingest.Stream
+type Stream struct {
+	mu    sync.Mutex
+	next  int
+	sinks map[int]ingest.Sink
+}
+
+func (s *Stream) Subscribe(sink ingest.Sink) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sinks == nil {
+		s.sinks = make(map[int]ingest.Sink)
+	}
+	id := s.next
+	s.next++
+	s.sinks[id] = sink
+	return func() {
+		s.mu.Lock()
+		delete(s.sinks, id)
+		s.mu.Unlock()
+	}, nil
+}
+
+// 구독자 수
+func (s *Stream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sinks)
+}
+
+func (s *Stream) Send(pkts ...*rtp.Packet) {
+	s.mu.Lock()
+	sinks := make([]ingest.Sink, 0, len(s.sinks))
+	for _, sink := range s.sinks {
+		sinks = append(sinks, sink)
+	}
+	s.mu.Unlock()
+	for _, pkt := range pkts {
+		for _, sink := range sinks {
+			sink(pkt)
+		}
+	}
+}
+
+// 30fps H.264 RTP 패킷 생성기. GOP 프레임마다 STAP-A(SPS, PPS)가 앞에 붙은 IDR을 만든다.
+type H264 struct {
+	GOP int    // 키프레임 간격 (0이면 30)
+	TS  uint32 // 다음 프레임의 RTP 타임스탬프
+
+	seq   uint16
+	frame int
+}
+
+// 다음 프레임의 패킷. 마지막 패킷에 마커 비트가 붙는다.
+func (g *H264) Next() (pkts []*rtp.Packet, key bool) {
+	gop := g.GOP
+	if gop <= 0 {
+		gop = 30
+	}
+	key = g.frame%gop == 0
+	var payloads [][]byte
+	if key {
+		stap := []byte{24}
+		for _, n := range [][]byte{SPS, PPS} {
+			stap = append(stap, byte(len(n)>>8), byte(len(n)))
+			stap = append(stap, n...)
+		}
+		payloads = append(payloads, stap, []byte{0x65, 0x88, 0x84, byte(g.frame)})
+	} else {
+		payloads = append(payloads, []byte{0x41, 0x9a, 0x02, byte(g.frame)})
+	}
+	for i, p := range payloads {
+		g.seq++
+		pkts = append(pkts, &rtp.Packet{
+			Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: g.seq, Timestamp: g.TS, Marker: i == len(payloads)-1, SSRC: 1},
+			Payload: p,
+		})
+	}
+	g.frame++
+	g.TS += FrameTicks
+	return pkts, key
+}
+
+// 스테레오 Opus 패킷 생성기 (20ms)
+type Opus struct {
+	TS  uint32
+	seq uint16
+}
+
+func (g *Opus) Next() *rtp.Packet {
+	g.seq++
+	pkt := &rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: 97, SequenceNumber: g.seq, Timestamp: g.TS, SSRC: 2},
+		Payload: []byte{0xfc, 0xff, 0xfe},
+	}
+	g.TS += AudioTicks
+	return pkt
+}
@@ -0,0 +1,341 @@
+package media
+
+import (
+	"encoding/binary"
+)
+
+// ---------- Fragmented MP4 ----------
+
+const (
+	MP4TrackVideo = "video"
+	MP4TrackAudio = "audio"
+	MP4TrackText  = "text" // 3GPP timed text (tx3g)
+)
+
+// 샘플 플래그 (ISO/IEC 14496-12 8.8.3.1)
+const (
+	mp4SampleFlagsSync    = 0x02000000 // sample_depends_on=2 (독립 프레임)
+	mp4SampleFlagsNonSync = 0x01010000 // sample_depends_on=1, is_non_sync_sample
+)
+
+type MP4Track struct {
+	ID        uint32
+	Kind      string
+	Timescale uint32
+
+	// video
+	Width, Height int
+	SPS, PPS      []byte
+
+	// audio (Opus)
+	Channels int
+}
+
+type MP4Sample struct {
+	Data     []byte
+	Duration uint32
+	Sync     bool
+}
+
+type MP4TrackFragment struct {
+	Track    *MP4Track
+	BaseTime uint64 // tfdt (트랙 timescale 단위)
+	Samples  []MP4Sample
+}
+
+// 크기 필드를 나중에 채우는 박스 작성기
+type mp4Writer struct {
+	buf []byte
+}
+
+func (w *mp4Writer) u8(v uint8)   { w.buf = append(w.buf, v) }
+func (w *mp4Writer) u16(v uint16) { w.buf = binary.BigEndian.AppendUint16(w.buf, v) }
+func (w *mp4Writer) u32(v uint32) { w.buf = binary.BigEndian.AppendUint32(w.buf, v) }
+func (w *mp4Writer) u64(v uint64) { w.buf = binary.BigEndian.AppendUint64(w.buf, v) }
+func (w *mp4Writer) bytes(b []byte) {
+	w.buf = append(w.buf, b...)
+}
+func (w *mp4Writer) zeros(n int) {
+	w.buf = append(w.buf, make([]byte, n)...)
+}
+
+func (w *mp4Writer) box(typ string, body func()) {
+	start := len(w.buf)
+	w.u32(0)
+	w.bytes([]byte(typ))
+	body()
+	binary.BigEndian.PutUint32(w.buf[start:], uint32(len(w.buf)-start))
+}
+
+func (w *mp4Writer) fullBox(typ string, version uint8, flags uint32, body func()) {
+	w.box(typ, func() {
+		w.u32(uint32(version)<<24 | flags&0xFFFFFF)
+		body()
+	})
+}
+
+func (w *mp4Writer) matrix() {
+	for _, v := range []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000} {
+		w.u32(v)
+	}
+}
+
+// ftyp + moov (초기화 세그먼트)
+func BuildMP4Init(tracks []*MP4Track) []byte {
+	w := &mp4Writer{}
+	w.box("ftyp", func() {
+		w.bytes([]byte("isom"))
+		w.u32(0x200)
+		for _, brand := range []string{"isom", "iso6", "mp41"} {
+			w.bytes([]byte(brand))
+		}
+	})
+
+	w.box("moov", func() {
+		w.fullBox("mvhd", 0, 0, func() {
+			w.u32(0)    // creation_time
+			w.u32(0)    // modification_time
+			w.u32(1000) // timescale
+			w.u32(0)    // duration (조각에서 결정)
+			w.u32(0x00010000)
+			w.u16(0x0100)
+			w.zeros(10)
+			w.matrix()
+			w.zeros(24)
+			w.u32(uint32(len(tracks) + 1)) // next_track_ID
+		})
+		for _, t := range tracks {
+			w.trak(t)
+		}
+		w.box("mvex", func() {
+			for _, t := range tracks {
+				w.fullBox("trex", 0, 0, func() {
+					w.u32(t.ID)
+					w.u32(1) // sample_description_index
+					w.u32(0)
+					w.u32(0)
+					w.u32(0)
+				})
+			}
+		})
+	})
+	return w.buf
+}
+
+func (w *mp4Writer) trak(t *MP4Track) {
+	w.box("trak", func() {
+		w.fullBox("tkhd", 0, 3, func() { // enabled | in_movie
+			w.u32(0)
+			w.u32(0)
+			w.u32(t.ID)
+			w.u32(0)
+			w.u32(0) // duration
+			w.zeros(8)
+			w.u16(0) // layer
+			w.u16(0) // alternate_group
+			if t.Kind == MP4TrackAudio {
+				w.u16(0x0100)
+			} else {
+				w.u16(0)
+			}
+			w.u16(0)
+			w.matrix()
+			w.u32(uint32(t.Width) << 16)
+			w.u32(uint32(t.Height) << 16)
+		})
+		w.box("mdia", func() {
+			w.fullBox("mdhd", 0, 0, func() {
+				w.u32(0)
+				w.u32(0)
+				w.u32(t.Timescale)
+				w.u32(0)
+				w.u16(0x55C4) // "und"
+				w.u16(0)
+			})
+			w.fullBox("hdlr", 0, 0, func() {
+				w.u32(0)
+				handler, name := t.handler()
+				w.bytes([]byte(handler))
+				w.zeros(12)
+				w.bytes(append([]byte(name), 0))
+			})
+			w.box("minf", func() {
+				w.mediaHeader(t)
+				w.box("dinf", func() {
+					w.fullBox("dref", 0, 0, func() {
+						w.u32(1)
+						w.fullBox("url ", 0, 1, func() {}) // self-contained
+					})
+				})
+				w.box("stbl", func() {
+					w.fullBox("stsd", 0, 0, func() {
+						w.u32(1)
+						w.sampleEntry(t)
+					})
+					w.fullBox("stts", 0, 0, func() { w.u32(0) })
+					w.fullBox("stsc", 0, 0, func() { w.u32(0) })
+					w.fullBox("stsz", 0, 0, func() { w.u32(0); w.u32(0) })
+					w.fullBox("stco", 0, 0, func() { w.u32(0) })
+				})
+			})
+		})
+	})
+}
+
+func (t *MP4Track) handler() (string, string) {
+	switch t.Kind {
+	case MP4TrackVideo:
+		return "vide", "VideoHandler"
+	case MP4TrackAudio:
+		return "soun", "SoundHandler"
+	case MP4TrackText:
+		return "sbtl", "SubtitleHandler"
+	}
+	return "meta", "MetaHandler"
+}
+
+func (w *mp4Writer) mediaHeader(t *MP4Track) {
+	switch t.Kind {
+	case MP4TrackVideo:
+		w.fullBox("vmhd", 0, 1, func() { w.zeros(8) })
+	case MP4TrackAudio:
+		w.fullBox("smhd", 0, 0, func() { w.zeros(4) })
+	default:
+		w.fullBox("nmhd", 0, 0, func() {})
+	}
+}
+
+func (w *mp4Writer) sampleEntry(t *MP4Track) {
+	switch t.Kind {
+	case MP4TrackVideo:
+		w.box("avc1", func() {
+			w.zeros(6)
+			w.u16(1) // data_reference_index
+			w.zeros(16)
+			w.u16(uint16(t.Width))
+			w.u16(uint16(t.Height))
+			w.u32(0x00480000) // 72 dpi
+			w.u32(0x00480000)
+			w.u32(0)
+			w.u16(1) // frame_count
+			w.zeros(32)
+			w.u16(0x0018)
+			w.u16(0xFFFF)
+			w.box("avcC", func() {
+				w.u8(1)
+				w.u8(t.SPS[1]) // profile
+				w.u8(t.SPS[2]) // compatibility
+				w.u8(t.SPS[3]) // level
+				w.u8(0xFF)     // 4바이트 NAL 길이
+				w.u8(0xE1)     // SPS 1개
+				w.u16(uint16(len(t.SPS)))
+				w.bytes(t.SPS)
+				w.u8(1)
+				w.u16(uint16(len(t.PPS)))
+				w.bytes(t.PPS)
+			})
+		})
+
+	case MP4TrackAudio:
+		w.box("Opus", func() {
+			w.zeros(6)
+			w.u16(1)
+			w.zeros(8)
+			w.u16(uint16(t.Channels))
+			w.u16(16)
+			w.zeros(4)
+			w.u32(48000 << 16)
+			w.box("dOps", func() {
+				w.u8(0)
+				w.u8(uint8(t.Channels))
+				w.u16(312) // pre-skip
+				w.u32(48000)
+				w.u16(0) // output gain
+				w.u8(0)  // channel mapping family
+			})
+		})
+
+	case MP4TrackText:
+		// 3GPP TS 26.245 5.16
+		w.box("tx3g", func() {
+			w.zeros(6)
+			w.u16(1)
+			w.u32(0)          // displayFlags
+			w.u8(1)           // 가로 가운데 정렬
+			w.u8(0xFF)        // 세로 아래 정렬 (-1)
+			w.u32(0)          // 배경색 RGBA
+			w.zeros(8)        // 기본 텍스트 상자
+			w.u16(0)          // StyleRecord startChar
+			w.u16(0)          // endChar
+			w.u16(1)          // font-ID
+			w.u8(0)           // face-style-flags
+			w.u8(18)          // font-size
+			w.u32(0xFFFFFFFF) // 글자색 RGBA
+			w.box("ftab", func() {
+				w.u16(1)
+				w.u16(1)
+				w.u8(uint8(len(mp4TextFont)))
+				w.bytes([]byte(mp4TextFont))
+			})
+		})
+	}
+}
+
+const mp4TextFont = "Sans-Serif"
+
+// tx3g 샘플: 2바이트 길이 + UTF-8 텍스트 (빈 샘플은 자막 없음 구간)
+func TX3GSample(text string) []byte {
+	if len(text) > 0xFFFF {
+		text = text[:0xFFFF]
+	}
+	return append([]byte{byte(len(text) >> 8), byte(len(text))}, text...)
+}
+
+// moof + mdat (미디어 조각)
+func BuildMP4Fragment(seq uint32, frags []MP4TrackFragment) []byte {
+	w := &mp4Writer{}
+	var offsetPos []int
+
+	w.box("moof", func() {
+		w.fullBox("mfhd", 0, 0, func() { w.u32(seq) })
+		for _, f := range frags {
+			w.box("traf", func() {
+				w.fullBox("tfhd", 0, 0x020000, func() { w.u32(f.Track.ID) }) // default-base-is-moof
+				w.fullBox("tfdt", 1, 0, func() { w.u64(f.BaseTime) })
+				// data-offset | sample-duration | sample-size | sample-flags
+				w.fullBox("trun", 0, 0x000701, func() {
+					w.u32(uint32(len(f.Samples)))
+					offsetPos = append(offsetPos, len(w.buf))
+					w.u32(0)
+					for _, s := range f.Samples {
+						w.u32(s.Duration)
+						w.u32(uint32(len(s.Data)))
+						if s.Sync {
+							w.u32(mp4SampleFlagsSync)
+						} else {
+							w.u32(mp4SampleFlagsNonSync)
+						}
+					}
+				})
+			})
+		}
+	})
+
+	// trun data_offset은 moof 시작부터 해당 트랙의 첫 샘플까지의 거리
+	offset := len(w.buf) + 8
+	for i, f := range frags {
+		binary.BigEndian.PutUint32(w.buf[offsetPos[i]:], uint32(offset))
+		for _, s := range f.Samples {
+			offset += len(s.Data)
+		}
+	}
+
+	w.box("mdat", func() {
+		for _, f := range frags {
+			for _, s := range f.Samples {
+				w.bytes(s.Data)
+			}
+		}
+	})
+	return w.buf
+}
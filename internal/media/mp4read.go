@@ -0,0 +1,340 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// ---------- Fragmented MP4 reading ----------
+
+// 녹화 파일에서 읽은 샘플 (DTS는 트랙 timescale 단위)
+type MP4ReadSample struct {
+	Track    *MP4Track
+	DTS      int64
+	Duration uint32
+	Sync     bool
+	Data     []byte
+}
+
+var errMP4Malformed = errors.New("mp4: malformed box")
+
+type mp4Box struct {
+	typ  string
+	body []byte
+}
+
+// 메모리에 있는 박스 목록을 순회
+func mp4Boxes(data []byte, visit func(b mp4Box) error) error {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return errMP4Malformed
+			}
+			size, hdr = binary.BigEndian.Uint64(data[8:]), 16
+		}
+		if size < hdr || size > uint64(len(data)) {
+			return errMP4Malformed
+		}
+		if err := visit(mp4Box{typ: typ, body: data[hdr:size]}); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// 필요한 자식 박스만 찾아 내려간다
+func mp4Child(data []byte, path ...string) []byte {
+	for _, typ := range path {
+		var found []byte
+		mp4Boxes(data, func(b mp4Box) error {
+			if found == nil && b.typ == typ {
+				found = b.body
+			}
+			return nil
+		})
+		if found == nil {
+			return nil
+		}
+		data = found
+	}
+	return data
+}
+
+// 조각 MP4 파일을 앞에서부터 읽어 조각(moof+mdat)마다 샘플을 넘긴다.
+// 기록 중이라 끝이 잘린 파일은 마지막 완전한 조각까지 읽는다.
+// visit이 false를 반환하면 읽기를 멈춘다.
+func ReadMP4Fragments(path string, visit func(samples []MP4ReadSample) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReaderSize(f, 64<<10)
+
+	tracks := make(map[uint32]*MP4Track)
+	var moof []byte
+	var moofPos, pos int64
+	for {
+		var hdr [16]byte
+		if _, err := io.ReadFull(br, hdr[:8]); err != nil {
+			return nil // 파일 끝 (또는 잘린 헤더)
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:]))
+		typ := string(hdr[4:8])
+		hdrLen := int64(8)
+		if size == 1 {
+			if _, err := io.ReadFull(br, hdr[8:16]); err != nil {
+				return nil
+			}
+			size, hdrLen = int64(binary.BigEndian.Uint64(hdr[8:])), 16
+		}
+		if size == 0 || size < hdrLen {
+			return nil
+		}
+		body := make([]byte, size-hdrLen)
+		if typ != "moov" && typ != "moof" && typ != "mdat" {
+			body = nil
+		}
+		if body != nil {
+			if _, err := io.ReadFull(br, body); err != nil {
+				return nil
+			}
+		} else if _, err := br.Discard(int(size - hdrLen)); err != nil {
+			return nil
+		}
+
+		switch typ {
+		case "moov":
+			if err := parseMP4Tracks(body, tracks); err != nil {
+				return err
+			}
+		case "moof":
+			moof, moofPos = body, pos
+		case "mdat":
+			if moof == nil {
+				break
+			}
+			samples, err := parseMP4Fragment(moof, moofPos, pos+hdrLen, body, tracks)
+			if err != nil {
+				return err
+			}
+			moof = nil
+			if !visit(samples) {
+				return nil
+			}
+		}
+		pos += size
+	}
+}
+
+func parseMP4Tracks(moov []byte, tracks map[uint32]*MP4Track) error {
+	return mp4Boxes(moov, func(b mp4Box) error {
+		if b.typ != "trak" {
+			return nil
+		}
+		t := &MP4Track{}
+		tkhd := mp4Child(b.body, "tkhd")
+		mdhd := mp4Child(b.body, "mdia", "mdhd")
+		hdlr := mp4Child(b.body, "mdia", "hdlr")
+		stsd := mp4Child(b.body, "mdia", "minf", "stbl", "stsd")
+		if len(tkhd) < 24 || len(mdhd) < 24 || len(hdlr) < 12 || len(stsd) < 8 {
+			return errMP4Malformed
+		}
+		if tkhd[0] == 1 {
+			t.ID = binary.BigEndian.Uint32(tkhd[20:])
+		} else {
+			t.ID = binary.BigEndian.Uint32(tkhd[12:])
+		}
+		if mdhd[0] == 1 {
+			if len(mdhd) < 24 {
+				return errMP4Malformed
+			}
+			t.Timescale = binary.BigEndian.Uint32(mdhd[20:])
+		} else {
+			t.Timescale = binary.BigEndian.Uint32(mdhd[12:])
+		}
+
+		entries := stsd[8:]
+		switch string(hdlr[8:12]) {
+		case "vide":
+			t.Kind = MP4TrackVideo
+			avc1 := mp4Child(entries, "avc1")
+			if len(avc1) < 78 {
+				return errMP4Malformed
+			}
+			t.Width = int(binary.BigEndian.Uint16(avc1[24:]))
+			t.Height = int(binary.BigEndian.Uint16(avc1[26:]))
+			t.SPS, t.PPS = parseAVCC(mp4Child(avc1[78:], "avcC"))
+		case "soun":
+			t.Kind = MP4TrackAudio
+			t.Channels = 2
+			if opus := mp4Child(entries, "Opus"); len(opus) >= 28 {
+				if dops := mp4Child(opus[28:], "dOps"); len(dops) >= 2 {
+					t.Channels = int(dops[1])
+				}
+			}
+		case "sbtl", "text":
+			t.Kind = MP4TrackText
+		default:
+			return nil
+		}
+		tracks[t.ID] = t
+		return nil
+	})
+}
+
+// avcC 설정에서 첫 SPS/PPS
+func parseAVCC(avcC []byte) (sps, pps []byte) {
+	if len(avcC) < 7 {
+		return nil, nil
+	}
+	buf := avcC[5:]
+	next := func() []byte {
+		if len(buf) < 2 {
+			return nil
+		}
+		n := int(binary.BigEndian.Uint16(buf))
+		if 2+n > len(buf) {
+			buf = nil
+			return nil
+		}
+		nalu := buf[2 : 2+n]
+		buf = buf[2+n:]
+		return nalu
+	}
+	numSPS := int(buf[0] & 0x1F)
+	buf = buf[1:]
+	for i := 0; i < numSPS; i++ {
+		if n := next(); sps == nil {
+			sps = n
+		}
+	}
+	if len(buf) < 1 {
+		return sps, nil
+	}
+	numPPS := int(buf[0])
+	buf = buf[1:]
+	for i := 0; i < numPPS; i++ {
+		if n := next(); pps == nil {
+			pps = n
+		}
+	}
+	return sps, pps
+}
+
+// moof의 트랙별 trun을 mdat 데이터와 연결한다
+func parseMP4Fragment(moof []byte, moofPos, mdatPos int64, mdat []byte, tracks map[uint32]*MP4Track) ([]MP4ReadSample, error) {
+	var samples []MP4ReadSample
+	err := mp4Boxes(moof, func(b mp4Box) error {
+		if b.typ != "traf" {
+			return nil
+		}
+		tfhd := mp4Child(b.body, "tfhd")
+		if len(tfhd) < 8 {
+			return errMP4Malformed
+		}
+		flags := binary.BigEndian.Uint32(tfhd) & 0xFFFFFF
+		track := tracks[binary.BigEndian.Uint32(tfhd[4:])]
+		rd := tfhd[8:]
+		field := func(n int) uint64 {
+			if len(rd) < n {
+				return 0
+			}
+			var v uint64
+			for _, c := range rd[:n] {
+				v = v<<8 | uint64(c)
+			}
+			rd = rd[n:]
+			return v
+		}
+		base := moofPos
+		if flags&0x01 != 0 {
+			base = int64(field(8))
+		}
+		if flags&0x02 != 0 {
+			field(4) // sample_description_index
+		}
+		var defDuration, defSize, defFlags uint32
+		if flags&0x08 != 0 {
+			defDuration = uint32(field(4))
+		}
+		if flags&0x10 != 0 {
+			defSize = uint32(field(4))
+		}
+		if flags&0x20 != 0 {
+			defFlags = uint32(field(4))
+		}
+
+		var dts int64
+		if tfdt := mp4Child(b.body, "tfdt"); len(tfdt) >= 8 {
+			if tfdt[0] == 1 && len(tfdt) >= 12 {
+				dts = int64(binary.BigEndian.Uint64(tfdt[4:]))
+			} else {
+				dts = int64(binary.BigEndian.Uint32(tfdt[4:]))
+			}
+		}
+
+		return mp4Boxes(b.body, func(tb mp4Box) error {
+			if tb.typ != "trun" || track == nil {
+				return nil
+			}
+			if len(tb.body) < 8 {
+				return errMP4Malformed
+			}
+			flags := binary.BigEndian.Uint32(tb.body) & 0xFFFFFF
+			count := binary.BigEndian.Uint32(tb.body[4:])
+			rd = tb.body[8:]
+			offset := base
+			if flags&0x01 != 0 {
+				offset += int64(int32(field(4)))
+			}
+			firstFlags, hasFirst := uint32(0), flags&0x04 != 0
+			if hasFirst {
+				firstFlags = uint32(field(4))
+			}
+			for i := uint32(0); i < count; i++ {
+				duration, size, sflags := defDuration, defSize, defFlags
+				if flags&0x100 != 0 {
+					duration = uint32(field(4))
+				}
+				if flags&0x200 != 0 {
+					size = uint32(field(4))
+				}
+				if flags&0x400 != 0 {
+					sflags = uint32(field(4))
+				} else if i == 0 && hasFirst {
+					sflags = firstFlags
+				}
+				if flags&0x800 != 0 {
+					field(4) // composition time offset
+				}
+
+				start := offset - mdatPos
+				if start < 0 || start+int64(size) > int64(len(mdat)) {
+					return errMP4Malformed
+				}
+				samples = append(samples, MP4ReadSample{
+					Track:    track,
+					DTS:      dts,
+					Duration: duration,
+					Sync:     track.Kind != MP4TrackVideo || sflags&0x10000 == 0,
+					Data:     mdat[start : start+int64(size)],
+				})
+				offset += int64(size)
+				dts += int64(duration)
+			}
+			return nil
+		})
+	})
+	return samples, err
+}
@@ -0,0 +1,131 @@
+package media
+
+import (
+	"math"
+	"time"
+)
+
+// ---------- Media timeline ----------
+
+const (
+	VideoClockRate = 90000
+	AudioClockRate = 48000
+)
+
+// 32비트 RTP 타임스탬프를 wrap-around 없이 누적
+type RTPTimeline struct {
+	Started bool
+	last    uint32
+	ext     int64
+}
+
+func (t *RTPTimeline) Extend(ts uint32) int64 {
+	if !t.Started {
+		t.Started = true
+		t.last = ts
+		return 0
+	}
+	t.ext += int64(int32(ts - t.last))
+	t.last = ts
+	return t.ext
+}
+
+type TimedSample struct {
+	DTS  int64
+	Data []byte
+	Sync bool
+}
+
+// 트랙 하나의 샘플을 조각 단위로 모은다. 샘플 길이는 다음 샘플이 와야 알 수 있으므로
+// 마지막 샘플 하나는 pending으로 보관한다.
+type TrackBuffer struct {
+	Track     *MP4Track
+	Base      int64 // 녹화 시작 기준 오프셋 (track timescale)
+	Clock     RTPTimeline
+	Pending   *TimedSample
+	lastDur   uint32
+	end       int64 // 기록된 마지막 샘플의 끝
+	fragStart int64
+	samples   []MP4Sample
+	Count     int
+}
+
+func (b *TrackBuffer) Push(s TimedSample) {
+	if p := b.Pending; p != nil {
+		dur := s.DTS - p.DTS
+		if dur <= 0 {
+			dur = 1
+		}
+		b.Add(p, uint32(dur))
+	}
+	b.Pending = &s
+}
+
+func (b *TrackBuffer) Add(p *TimedSample, dur uint32) {
+	if len(b.samples) == 0 {
+		b.fragStart = p.DTS
+	}
+	b.samples = append(b.samples, MP4Sample{Data: p.Data, Duration: dur, Sync: p.Sync})
+	b.end = p.DTS + int64(dur)
+	b.lastDur = dur
+	b.Count++
+}
+
+// 녹화 종료 시 마지막 샘플은 직전 샘플 길이로 마무리
+func (b *TrackBuffer) Drain() {
+	if p := b.Pending; p != nil {
+		dur := b.lastDur
+		if dur == 0 {
+			dur = b.Track.Timescale / 30
+		}
+		b.Add(p, dur)
+		b.Pending = nil
+	}
+}
+
+func (b *TrackBuffer) Take() (MP4TrackFragment, bool) {
+	if len(b.samples) == 0 {
+		return MP4TrackFragment{}, false
+	}
+	frag := MP4TrackFragment{Track: b.Track, BaseTime: uint64(b.fragStart), Samples: b.samples}
+	b.samples = nil
+	return frag, true
+}
+
+func (b *TrackBuffer) toDuration(ticks int64) time.Duration {
+	return TicksToDuration(ticks, int64(b.Track.Timescale))
+}
+
+// 클럭 단위 시간을 Duration으로. 초 단위를 먼저 나눠 긴 녹화(90kHz에서 약 28시간 이상)에서도 넘치지 않는다.
+func TicksToDuration(ticks, rate int64) time.Duration {
+	return time.Duration(ticks/rate)*time.Second + time.Duration(ticks%rate)*time.Second/time.Duration(rate)
+}
+
+// 현재 조각의 길이 (보관 중인 샘플 시작 지점까지)
+func (b *TrackBuffer) FragmentLength() time.Duration {
+	if len(b.samples) == 0 {
+		return 0
+	}
+	end := b.end
+	if b.Pending != nil {
+		end = b.Pending.DTS
+	}
+	return b.toDuration(end - b.fragStart)
+}
+
+// 녹화 시작부터 지금까지의 길이
+func (b *TrackBuffer) Position() time.Duration {
+	if b.Track == nil {
+		return 0
+	}
+	end := b.end
+	if b.Pending != nil && b.Pending.DTS > end {
+		end = b.Pending.DTS
+	}
+	return b.toDuration(end)
+}
+
+// 밀리초 단위로 반올림한 초
+func RoundSeconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
@@ -0,0 +1,26 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicksToDuration(t *testing.T) {
+	tests := []struct {
+		ticks, rate int64
+		want        time.Duration
+	}{
+		{0, VideoClockRate, 0},
+		{45000, VideoClockRate, 500 * time.Millisecond},
+		{-90000, VideoClockRate, -time.Second},
+		{960, AudioClockRate, 20 * time.Millisecond},
+		// 100시간 - ticks*time.Second는 int64를 넘는다
+		{100 * 3600 * VideoClockRate, VideoClockRate, 100 * time.Hour},
+		{100*3600*VideoClockRate + 90, VideoClockRate, 100*time.Hour + time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := TicksToDuration(tt.ticks, tt.rate); got != tt.want {
+			t.Errorf("TicksToDuration(%d, %d) = %s, want %s", tt.ticks, tt.rate, got, tt.want)
+		}
+	}
+}
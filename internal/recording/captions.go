@@ -0,0 +1,105 @@
+package recording
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- Recording captions ----------
+
+const (
+	textTimescale   = 1000 // 자막 트랙은 ms 단위
+	recordingVTTExt = ".vtt"
+)
+
+// 녹화 중인 모든 세그먼트에 최종 자막을 기록한다
+func (m *Manager) AddSubtitle(subtitle subtitles.Data, at time.Time) {
+	if !subtitle.IsFinal || strings.TrimSpace(subtitle.Text) == "" {
+		return
+	}
+	m.mu.Lock()
+	recs := []*Recorder{m.manual}
+	if m.continuous != nil {
+		recs = append(recs, m.continuous.rec)
+	}
+	m.mu.Unlock()
+
+	for _, rec := range recs {
+		if rec != nil {
+			rec.AddSubtitle(subtitle, at)
+		}
+	}
+}
+
+// 세그먼트 타임라인(첫 키프레임 기준)에 맞춰 자막을 기록한다.
+// 키프레임 전이나 녹화가 멈춘 동안 받은 자막은 버린다.
+func (r *Recorder) AddSubtitle(subtitle subtitles.Data, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seg := r.seg
+	if r.closed || seg == nil || !seg.initialized {
+		return
+	}
+
+	offset := at.Sub(seg.mediaStart)
+	if last := len(seg.subtitles); last > 0 && offset < seg.subtitles[last-1].Offset {
+		offset = seg.subtitles[last-1].Offset
+	}
+	seg.settleText(offset)
+	seg.subtitles = append(seg.subtitles, subtitles.TranscriptEntry{Offset: offset, Received: at, Subtitle: subtitle})
+}
+
+// 보관 중인 마지막 자막을 until에서 끝내고 (최대 subtitles.DefaultCueDuration),
+// until까지 남은 구간은 빈 샘플로 채운다. 표시 규칙은 subtitles.TranscriptSession.cues와 같다.
+func (seg *recordingSegment) settleText(until time.Duration) {
+	end := until.Milliseconds()
+	if n := len(seg.subtitles); n > seg.textCues {
+		cue := seg.subtitles[n-1]
+		start := cue.Offset.Milliseconds()
+		if start < seg.textEnd {
+			start = seg.textEnd
+		}
+		stop := min(end, (cue.Offset + subtitles.DefaultCueDuration).Milliseconds())
+		if stop > start {
+			text := subtitles.CueText(cue.Subtitle, subtitles.TranscriptOptions{})
+			seg.text.Add(&media.TimedSample{DTS: start, Data: media.TX3GSample(text), Sync: true}, uint32(stop-start))
+			seg.textEnd = stop
+		}
+		seg.textCues = n
+	}
+	if end > seg.textEnd {
+		seg.text.Add(&media.TimedSample{DTS: seg.textEnd, Data: media.TX3GSample(""), Sync: true}, uint32(end-seg.textEnd))
+		seg.textEnd = end
+	}
+}
+
+// 세그먼트 길이에 맞춘 자막 구간
+func (seg *recordingSegment) cues() []subtitles.Cue {
+	sess := subtitles.TranscriptSession{
+		StartedAt: seg.mediaStart,
+		EndedAt:   seg.mediaStart.Add(seg.video.Position()),
+		Entries:   seg.subtitles,
+	}
+	return sess.Cues()
+}
+
+// 녹화 파일 옆에 같은 타임라인의 WebVTT 사이드카를 쓴다
+func writeRecordingVTT(path string, cues []subtitles.Cue) error {
+	vtt := recordingSidecar(path, recordingVTTExt)
+	f, err := os.Create(vtt + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err := subtitles.WriteWebVTT(f, cues, subtitles.TranscriptOptions{}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(vtt+".tmp", vtt)
+}
@@ -0,0 +1,58 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/subtitles"
+)
+
+// 줄바꿈과 "-->"가 들어간 자막이 사이드카/자막 트랙을 깨지 않아야 한다
+const unsafeCueText = "a\n\nb <i>x</i> --> y"
+
+func TestWriteRecordingVTTEscapesCueText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec-20260101-000000.mp4")
+	cues := []subtitles.Cue{
+		{Start: 0, End: time.Second, Subtitle: subtitles.Data{Text: unsafeCueText}},
+		{Start: time.Second, End: 2 * time.Second, Subtitle: subtitles.Data{Text: "next"}},
+	}
+	if err := writeRecordingVTT(path, cues); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(recordingSidecar(path, recordingVTTExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vtt := string(data)
+
+	want := "WEBVTT\n\n" +
+		"1\n00:00:00.000 --> 00:00:01.000\na b &lt;i&gt;x&lt;/i&gt; --&gt; y\n\n" +
+		"2\n00:00:01.000 --> 00:00:02.000\nnext\n\n"
+	if vtt != want {
+		t.Errorf("sidecar:\n%q\nwant:\n%q", vtt, want)
+	}
+	if n := strings.Count(vtt, "-->"); n != len(cues) {
+		t.Errorf("found %d timing arrows, want %d", n, len(cues))
+	}
+}
+
+func TestSettleTextWritesSingleLineSample(t *testing.T) {
+	seg := &recordingSegment{}
+	seg.text.Track = &media.MP4Track{ID: 3, Kind: media.MP4TrackText, Timescale: textTimescale}
+	seg.subtitles = []subtitles.TranscriptEntry{{Subtitle: subtitles.Data{Text: unsafeCueText}}}
+
+	seg.settleText(time.Second)
+
+	frag, ok := seg.text.Take()
+	if !ok || len(frag.Samples) == 0 {
+		t.Fatal("no text samples")
+	}
+	got := string(frag.Samples[0].Data)
+	if want := string(media.TX3GSample("a b <i>x</i> --> y")); got != want {
+		t.Errorf("tx3g sample %q, want %q", got, want)
+	}
+}
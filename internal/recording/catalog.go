@@ -0,0 +1,246 @@
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------- Recording catalog ----------
+
+const (
+	recordingManualPrefix     = "rec-"
+	recordingNameTime         = "20060102-150405"
+	recordingStreamManual     = "manual"
+	recordingStreamContinuous = "continuous"
+
+	continuousDirName = "continuous"
+	recordingMetaExt  = ".json"
+)
+
+// 녹화 파일과 함께 지우는 사이드카 확장자
+var recordingSidecarExts = []string{recordingMetaExt, recordingVTTExt}
+
+var ErrNotFound = errors.New("recording not found")
+
+// 녹화 파일 하나의 정보. 세그먼트가 닫힐 때 같은 이름의 .json 사이드카로 저장된다.
+type Entry struct {
+	ID          string    `json:"id"`
+	Stream      string    `json:"stream"` // manual | continuous | clip
+	File        string    `json:"file"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+	Duration    float64   `json:"duration"` // 초
+	Bytes       int64     `json:"bytes"`
+	HasVideo    bool      `json:"has_video"`
+	HasAudio    bool      `json:"has_audio"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	Transcripts []string  `json:"transcripts,omitempty"` // 녹화 중 자막이 기록된 세션 ID
+	Subtitles   int       `json:"subtitles,omitempty"`   // 자막 트랙/WebVTT 사이드카의 자막 수
+	Active      bool      `json:"active,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	SubtitleURL string    `json:"subtitle_url,omitempty"`
+
+	path string
+}
+
+type recordingStream struct {
+	name   string
+	dir    string
+	prefix string
+}
+
+func (m *Manager) streams() []recordingStream {
+	return []recordingStream{
+		{recordingStreamManual, m.dir, recordingManualPrefix},
+		{recordingStreamContinuous, filepath.Join(m.dir, continuousDirName), continuousSegmentPrefix},
+		{recordingStreamClip, filepath.Join(m.dir, clipDirName), clipPrefix},
+	}
+}
+
+// prefix+시각.mp4, 같은 초에 이미 있으면 -2, -3 ... 을 붙인다
+func uniqueRecordingPath(dir, prefix string, t time.Time) string {
+	base := prefix + t.Format(recordingNameTime)
+	path := filepath.Join(dir, base+".mp4")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.mp4", base, n))
+	}
+}
+
+// 녹화 파일 경로
+func (e Entry) MediaPath() string {
+	return e.path
+}
+
+// 같은 타임라인의 WebVTT 사이드카 경로 (자막이 없으면 파일이 없을 수 있다)
+func (e Entry) SubtitlesPath() string {
+	return recordingSidecar(e.path, recordingVTTExt)
+}
+
+func recordingSidecar(path, ext string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// 임시 파일에 쓴 뒤 이름을 바꿔 목록 조회 중에 반쯤 쓰인 사이드카가 보이지 않게 한다
+func writeRecordingMeta(path string, e Entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	meta := recordingSidecar(path, recordingMetaExt)
+	if err := os.WriteFile(meta+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(meta+".tmp", meta)
+}
+
+func removeRecordingFiles(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, ext := range recordingSidecarExts {
+		os.Remove(recordingSidecar(path, ext))
+	}
+	return nil
+}
+
+// 사이드카가 없으면 (비정상 종료 등) 파일명의 시각과 수정 시각으로 추정
+func loadRecordingEntry(path, stream, prefix string) (Entry, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var e Entry
+	if data, err := os.ReadFile(recordingSidecar(path, recordingMetaExt)); err == nil {
+		if err := json.Unmarshal(data, &e); err != nil {
+			log.Printf("Recording catalog: %s: %v", filepath.Base(path), err)
+		}
+	}
+	if e.StartedAt.IsZero() {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix), ".mp4")
+		name = name[:min(len(name), len(recordingNameTime))] // -2 등 중복 번호 제외
+		if t, err := time.ParseInLocation(recordingNameTime, name, time.Local); err == nil {
+			e.StartedAt = t
+		} else {
+			e.StartedAt = fi.ModTime()
+		}
+		e.EndedAt = fi.ModTime()
+		if e.EndedAt.After(e.StartedAt) {
+			e.Duration = e.EndedAt.Sub(e.StartedAt).Seconds()
+		}
+		e.HasVideo = true
+	}
+
+	e.Stream = stream
+	e.File = filepath.Base(path)
+	e.Bytes = fi.Size()
+	e.path = path
+	e.fill()
+	return e, nil
+}
+
+func (e *Entry) fill() {
+	e.ID = strings.TrimSuffix(e.File, filepath.Ext(e.File))
+	e.URL = "/recordings/" + e.ID + "/media"
+	if e.Subtitles > 0 {
+		e.SubtitleURL = "/recordings/" + e.ID + "/subtitles.vtt"
+	}
+}
+
+// 진행 중인 녹화 세그먼트 (경로 기준)
+func (m *Manager) activeEntries() map[string]Entry {
+	m.mu.Lock()
+	recs := []*Recorder{m.manual}
+	if m.continuous != nil {
+		recs = append(recs, m.continuous.rec)
+	}
+	m.mu.Unlock()
+
+	active := make(map[string]Entry)
+	for _, rec := range recs {
+		if rec == nil {
+			continue
+		}
+		if e, ok := rec.ActiveEntry(); ok {
+			active[e.path] = e
+		}
+	}
+	return active
+}
+
+// 모든 녹화 파일 목록 (최근 것부터)
+func (m *Manager) Catalog() ([]Entry, error) {
+	active := m.activeEntries()
+
+	var list []Entry
+	for _, s := range m.streams() {
+		entries, err := os.ReadDir(s.dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, de := range entries {
+			name := de.Name()
+			if de.IsDir() || !strings.HasPrefix(name, s.prefix) || filepath.Ext(name) != ".mp4" {
+				continue
+			}
+			path := filepath.Join(s.dir, name)
+			if e, ok := active[path]; ok {
+				list = append(list, e)
+				continue
+			}
+			e, err := loadRecordingEntry(path, s.name, s.prefix)
+			if err != nil {
+				continue // 목록을 읽는 사이 보관 정책으로 삭제됨
+			}
+			list = append(list, e)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.After(list[j].StartedAt) })
+	return list, nil
+}
+
+func (m *Manager) Find(id string) (Entry, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return Entry{}, ErrNotFound
+	}
+	list, err := m.Catalog()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range list {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
+
+func (m *Manager) Delete(id string) error {
+	e, err := m.Find(id)
+	if err != nil {
+		return err
+	}
+	if e.Active {
+		return ErrActive
+	}
+	if err := removeRecordingFiles(e.path); err != nil {
+		return err
+	}
+	log.Printf("Recording deleted: %s", e.path)
+	return nil
+}
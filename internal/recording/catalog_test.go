@@ -0,0 +1,101 @@
+package recording
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"rec-20260101-100000.mp4",
+		"rec-20260101-100000-2.mp4",
+		"continuous/seg-20260101-110000.mp4",
+		"clips/clip-20260101-090000.mp4",
+		"notes.txt",
+		"rec-20260101-120000.json", // 영상 없는 사이드카
+	} {
+		touch(t, filepath.Join(dir, name))
+	}
+	m := NewManager(Config{Dir: dir})
+
+	list, err := m.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range list {
+		got = append(got, e.Stream+":"+e.ID)
+	}
+	// 최근 것부터 - 같은 초에 시작한 두 수동 녹화는 순서를 보지 않는다
+	want := []string{
+		"continuous:seg-20260101-110000",
+		"manual:rec-20260101-100000",
+		"manual:rec-20260101-100000-2",
+		"clip:clip-20260101-090000",
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[3] != want[3] ||
+		!slices.Contains(got, want[1]) || !slices.Contains(got, want[2]) {
+		t.Fatalf("Catalog() = %v, want %v", got, want)
+	}
+	for _, e := range list {
+		if e.Stream == recordingStreamManual && e.StartedAt.Hour() != 10 {
+			t.Errorf("%s started at %s, want the time from the file name", e.ID, e.StartedAt)
+		}
+	}
+}
+
+// 목록에 있는 ID만 찾고, 경로로 읽힐 수 있는 ID는 거절한다
+func TestFindRejectsUnsafeIDs(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, filepath.Join(dir, "rec-20260101-100000.mp4"))
+	touch(t, filepath.Join(dir, "secret.mp4"))
+	m := NewManager(Config{Dir: dir})
+
+	tests := []struct {
+		id   string
+		want error
+	}{
+		{"rec-20260101-100000", nil},
+		{"", ErrNotFound},
+		{"secret", ErrNotFound}, // 녹화 이름 형식이 아님
+		{"../rec-20260101-100000", ErrNotFound},
+		{"continuous/seg-20260101-110000", ErrNotFound},
+		{`..\rec-20260101-100000`, ErrNotFound},
+		{".rec-20260101-100000", ErrNotFound},
+		{"rec-20990101-000000", ErrNotFound},
+	}
+	for _, tt := range tests {
+		e, err := m.Find(tt.id)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Find(%q) error = %v, want %v", tt.id, err, tt.want)
+			continue
+		}
+		if err == nil && e.ID != tt.id {
+			t.Errorf("Find(%q) = %s", tt.id, e.ID)
+		}
+	}
+}
+
+func TestDeleteRemovesSidecars(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rec-20260101-100000.mp4")
+	touch(t, path)
+	for _, ext := range recordingSidecarExts {
+		touch(t, recordingSidecar(path, ext))
+	}
+	m := NewManager(Config{Dir: dir})
+	if err := m.Delete("rec-20260101-100000"); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("left %d files after Delete", len(entries))
+	}
+	if err := m.Delete("rec-20260101-100000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,252 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- Clips ----------
+
+const (
+	recordingStreamClip = "clip"
+	clipDirName         = "clips"
+	clipPrefix          = "clip-"
+
+	// 자막/시각 기준 클립의 앞뒤 기본 길이
+	ClipDefaultPadding = 15 * time.Second
+	ClipMaxDuration    = 10 * time.Minute
+	// 끝 시각이 아직 오지 않은 요청을 기다리는 최대 시간
+	ClipMaxWait = time.Minute
+)
+
+var ErrClipUnavailable = errors.New("no media available for the requested range")
+
+// 클립에 넣을 미디어를 녹화기에 기록하고 같은 구간의 자막을 반환
+type clipFeed func(rec *Recorder) []subtitles.TranscriptEntry
+
+// from~to 구간을 시간 이동 버퍼 또는 녹화 파일에서 잘라 클립으로 저장한다.
+// 영상은 from 직전 키프레임부터 시작하고, 자막은 자막 트랙과 WebVTT 사이드카로 함께 저장된다.
+func (m *Manager) Clip(ctx context.Context, from, to time.Time) (Entry, error) {
+	if wait := time.Until(to); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return Entry{}, ctx.Err()
+		}
+	}
+
+	feed := m.clipSource(from, to)
+	if feed == nil {
+		return Entry{}, ErrClipUnavailable
+	}
+	rec, err := openRecorder(m.clipPath(from), recorderOptions{Stream: recordingStreamClip, Transcripts: m.transcripts})
+	if err != nil {
+		return Entry{}, err
+	}
+	path := rec.CurrentPath()
+
+	entries := feed(rec)
+	if info := rec.Info(); info.HasVideo {
+		for _, e := range clipSubtitles(entries, info.StartedAt) {
+			rec.AddSubtitle(e.Subtitle, e.Received)
+		}
+	}
+	if err := rec.Stop(); err != nil {
+		removeRecordingFiles(path)
+		return Entry{}, err
+	}
+
+	// 키프레임을 하나도 받지 못한 파일은 녹화기가 지운다
+	e, err := loadRecordingEntry(path, recordingStreamClip, clipPrefix)
+	if err != nil {
+		return Entry{}, ErrClipUnavailable
+	}
+	return e, nil
+}
+
+// 같은 시각의 클립이 이미 있으면 번호를 붙인다
+func (m *Manager) clipPath(from time.Time) func() string {
+	return func() string {
+		dir := filepath.Join(m.dir, clipDirName)
+		name := clipPrefix + from.Format("20060102-150405")
+		path := filepath.Join(dir, name+".mp4")
+		for n := 2; ; n++ {
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				return path
+			}
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d.mp4", name, n))
+		}
+	}
+}
+
+// 시작 시점이 시간 이동 버퍼 안이면 버퍼에서, 아니면 녹화 파일에서 자른다.
+// 둘 다 없으면 버퍼에 남아 있는 부분만 사용한다.
+func (m *Manager) clipSource(from, to time.Time) clipFeed {
+	dvrFeed := func(rec *Recorder) []subtitles.TranscriptEntry {
+		for _, p := range m.timeShift.Range(from, to) {
+			if p.Video {
+				rec.writeVideo(p.Pkt, p.At)
+			} else {
+				rec.writeAudio(p.Pkt.Payload, p.Pkt.Timestamp, p.At)
+			}
+		}
+		if m.transcripts == nil {
+			return nil
+		}
+		return m.transcripts.EntriesBetween(from.Add(-subtitles.DefaultCueDuration), to)
+	}
+
+	oldest, buffered := time.Time{}, false
+	if m.timeShift != nil {
+		oldest, buffered = m.timeShift.Oldest()
+	}
+	if buffered && !oldest.After(from) {
+		return dvrFeed
+	}
+	if entries := m.clipRecordings(from, to); len(entries) > 0 {
+		return func(rec *Recorder) []subtitles.TranscriptEntry {
+			return feedClipFromRecordings(rec, entries, from, to)
+		}
+	}
+	if buffered && oldest.Before(to) {
+		return dvrFeed
+	}
+	return nil
+}
+
+// 구간과 겹치는 한 스트림의 녹화 파일 (시간순). from을 포함하는 스트림을 고르며 연속 녹화를 우선한다.
+func (m *Manager) clipRecordings(from, to time.Time) []Entry {
+	list, err := m.Catalog()
+	if err != nil {
+		log.Printf("Clip: recording catalog unavailable: %v", err)
+		return nil
+	}
+
+	var overlapping []Entry
+	for _, e := range list {
+		if e.Stream != recordingStreamClip && e.HasVideo && e.StartedAt.Before(to) && e.EndedAt.After(from) {
+			overlapping = append(overlapping, e)
+		}
+	}
+	sort.Slice(overlapping, func(i, j int) bool { return overlapping[i].StartedAt.Before(overlapping[j].StartedAt) })
+
+	stream := ""
+	for _, e := range overlapping {
+		if !e.StartedAt.After(from) && (stream == "" || e.Stream == recordingStreamContinuous) {
+			stream = e.Stream
+		}
+	}
+	if stream == "" && len(overlapping) > 0 {
+		stream = overlapping[0].Stream
+	}
+
+	var entries []Entry
+	for _, e := range overlapping {
+		if e.Stream == stream {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+type clipSample struct {
+	media.MP4ReadSample
+	at time.Time
+}
+
+// 녹화 파일의 샘플을 원래 시각에 맞춰 녹화기에 넣는다. 타임스탬프는 from 기준으로 다시 매겨
+// 파일이 바뀌어도 이어지게 하고, 자막 트랙의 자막은 그대로 돌려준다.
+func feedClipFromRecordings(rec *Recorder, entries []Entry, from, to time.Time) []subtitles.TranscriptEntry {
+	ticks := func(at time.Time, rate int64) uint32 {
+		return uint32(int64(at.Sub(from)) * rate / int64(time.Second))
+	}
+	feed := func(s clipSample) {
+		switch s.Track.Kind {
+		case media.MP4TrackVideo:
+			au := media.AVCCAccessUnit(s.Data, ticks(s.at, media.VideoClockRate))
+			if s.Sync && s.Track.SPS != nil && s.Track.PPS != nil {
+				au.NALUs = append([][]byte{s.Track.SPS, s.Track.PPS}, au.NALUs...)
+			}
+			rec.writeAccessUnit(au, s.at)
+		case media.MP4TrackAudio:
+			rec.writeAudio(s.Data, ticks(s.at, media.AudioClockRate), s.at)
+		}
+	}
+
+	var captions []subtitles.TranscriptEntry
+	var pending []clipSample // from 직전 키프레임부터 모은 샘플
+	started := false
+	for _, e := range entries {
+		done := false
+		err := media.ReadMP4Fragments(e.path, func(samples []media.MP4ReadSample) bool {
+			batch := make([]clipSample, len(samples))
+			for i, s := range samples {
+				offset := media.TicksToDuration(int64(s.DTS), int64(s.Track.Timescale))
+				batch[i] = clipSample{s, e.StartedAt.Add(offset)}
+			}
+			sort.SliceStable(batch, func(i, j int) bool { return batch[i].at.Before(batch[j].at) })
+
+			for _, s := range batch {
+				if !s.at.Before(to) {
+					done = true
+					continue
+				}
+				switch {
+				case s.Track.Kind == media.MP4TrackText:
+					if len(s.Data) > 2 {
+						captions = append(captions, subtitles.TranscriptEntry{
+							Received: s.at,
+							Subtitle: subtitles.Data{Text: string(s.Data[2:]), IsFinal: true},
+						})
+					}
+				case done:
+					// 자막 샘플은 다음 자막이 오거나 파일이 닫힐 때 기록되므로 파일 끝까지 읽는다
+				case started:
+					feed(s)
+				default:
+					if s.Track.Kind == media.MP4TrackVideo && s.Sync && !s.at.After(from) {
+						pending = pending[:0]
+					}
+					pending = append(pending, s)
+					if !s.at.Before(from) {
+						for _, p := range pending {
+							feed(p)
+						}
+						pending, started = nil, true
+					}
+				}
+			}
+			return true
+		})
+		if err != nil {
+			log.Printf("Clip: %s: %v", e.File, err)
+		}
+		if done {
+			break
+		}
+	}
+	return captions
+}
+
+// 클립 시작 전에 받은 자막은 시작 시점에도 표시 중인 마지막 하나만 남긴다
+func clipSubtitles(entries []subtitles.TranscriptEntry, start time.Time) []subtitles.TranscriptEntry {
+	i := 0
+	for i < len(entries) && !entries[i].Received.After(start) {
+		i++
+	}
+	if i > 0 && entries[i-1].Received.Add(subtitles.DefaultCueDuration).After(start) {
+		i--
+	}
+	return entries[i:]
+}
@@ -0,0 +1,128 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/media/mediatest"
+)
+
+// 30fps로 n프레임을 실제 시간에 맞춰 보내고, 키프레임을 보낸 시각을 반환한다
+func sendLive(video, audio *mediatest.Stream, vgen *mediatest.H264, agen *mediatest.Opus, n int) []time.Time {
+	var keys []time.Time
+	for i := 0; i < n; i++ {
+		pkts, key := vgen.Next()
+		if key {
+			keys = append(keys, time.Now())
+		}
+		video.Send(pkts...)
+		audio.Send(agen.Next())
+		time.Sleep(time.Second / 30)
+	}
+	return keys
+}
+
+// 클립 파일의 샘플
+func readClip(t *testing.T, e Entry) []media.MP4ReadSample {
+	t.Helper()
+	var samples []media.MP4ReadSample
+	err := media.ReadMP4Fragments(e.path, func(s []media.MP4ReadSample) bool {
+		samples = append(samples, s...)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func firstVideo(samples []media.MP4ReadSample) (media.MP4ReadSample, int) {
+	var first media.MP4ReadSample
+	n := 0
+	for _, s := range samples {
+		if s.Track.Kind == media.MP4TrackVideo {
+			if n == 0 {
+				first = s
+			}
+			n++
+		}
+	}
+	return first, n
+}
+
+// 시간 이동 버퍼에 있는 구간은 버퍼에서 자르고, 영상은 from 직전 키프레임부터 시작한다
+func TestClipFromTimeShift(t *testing.T) {
+	video, audio := &mediatest.Stream{}, &mediatest.Stream{}
+	buf := dvr.NewBuffer(time.Minute, 1<<20, video, audio)
+	m := NewManager(Config{Dir: t.TempDir(), Video: video, Audio: audio, TimeShift: buf})
+
+	keys := sendLive(video, audio, &mediatest.H264{GOP: 10}, &mediatest.Opus{}, 30)
+	from, to := keys[1].Add(50*time.Millisecond), keys[2].Add(50*time.Millisecond)
+
+	e, err := m.Clip(context.Background(), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Stream != recordingStreamClip || !e.HasVideo || !e.HasAudio {
+		t.Errorf("Clip() = %+v, want a clip with video and audio", e)
+	}
+	first, n := firstVideo(readClip(t, e))
+	if !first.Sync || first.Track.Width != mediatest.Width {
+		t.Errorf("clip starts with %+v, want a keyframe", first)
+	}
+	// 두 번째 키프레임부터 to까지 (GOP 하나 + 50ms)
+	if n < 10 || n > 14 {
+		t.Errorf("clip has %d frames, want about 11", n)
+	}
+
+	if list, err := m.Catalog(); err != nil || len(list) != 1 || list[0].ID != e.ID {
+		t.Errorf("Catalog() = %+v, %v; want the clip", list, err)
+	}
+}
+
+// 버퍼에 없는 구간은 녹화 파일에서 자르고, 버퍼도 녹화도 없으면 ErrClipUnavailable
+func TestClipFromRecordings(t *testing.T) {
+	video, audio := &mediatest.Stream{}, &mediatest.Stream{}
+	m := NewManager(Config{Dir: t.TempDir(), Video: video, Audio: audio})
+
+	if _, err := m.Clip(context.Background(), time.Now().Add(-time.Second), time.Now()); !errors.Is(err, ErrClipUnavailable) {
+		t.Fatalf("Clip() with no media = %v, want ErrClipUnavailable", err)
+	}
+
+	if _, err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	keys := sendLive(video, audio, &mediatest.H264{GOP: 10}, &mediatest.Opus{}, 30)
+	if _, err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := keys[1].Add(50*time.Millisecond), keys[2].Add(50*time.Millisecond)
+	e, err := m.Clip(context.Background(), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := readClip(t, e)
+	first, n := firstVideo(samples)
+	if !first.Sync || first.Track.SPS == nil {
+		t.Errorf("clip starts with %+v, want a keyframe with parameter sets", first)
+	}
+	if n < 10 || n > 14 {
+		t.Errorf("clip has %d frames, want about 11", n)
+	}
+	// 타임스탬프는 클립 시작부터 다시 매긴다
+	if first.DTS != 0 {
+		t.Errorf("first DTS = %d, want 0", first.DTS)
+	}
+
+	// 끝이 오지 않은 구간은 기다리다 컨텍스트가 끝나면 포기한다
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.Clip(ctx, from, time.Now().Add(time.Minute)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Clip() ending in the future = %v, want the context error", err)
+	}
+}
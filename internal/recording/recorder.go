@@ -0,0 +1,731 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/subtitles"
+	"webrtc-streamer/internal/sysstatus"
+)
+
+// ---------- Recording ----------
+
+const (
+	// 키프레임 사이 간격이 이보다 길어야 새 조각을 시작
+	recordingFragmentDuration = time.Second
+	// 영상 없이 오디오만 들어올 때 조각을 강제로 닫는 길이
+	recordingMaxFragmentDuration = 5 * time.Second
+	// 파일 쓰기 대기열 (조각 단위)
+	recordingWriteQueue = 32
+	// 수동 녹화 중 여유 공간 확인 주기
+	freeSpaceCheckInterval = 5 * time.Second
+)
+
+// RTP 수신을 구독해 H.264/Opus를 조각 MP4(fMP4) 파일로 기록한다.
+// 세그먼트(파일)마다 첫 키프레임(SPS/PPS 포함)이 들어온 시점이 0초가 된다.
+type Recorder struct {
+	StartedAt time.Time
+	stream    string
+
+	nextPath      func() string // 세그먼트를 열 때마다 호출
+	segmentLength time.Duration // 0이면 파일 하나에 계속 기록
+	allowSegment  func() error  // 새 세그먼트를 열기 전 확인 (수신 경로에서 불리므로 디스크를 건드리면 안 됨)
+	onSegment     func(Info)    // 키프레임이 기록된 세그먼트 파일이 닫힌 뒤 호출
+	transcripts   Transcripts
+
+	mu       sync.Mutex
+	closed   bool
+	err      error
+	paused   error // 새 세그먼트를 열지 못한 이유
+	depack   media.H264Depacketizer
+	channels int
+	seg      *recordingSegment
+	last     Info // 마지막으로 닫힌 세그먼트
+	closing  sync.WaitGroup
+
+	unsubVideo func()
+	unsubAudio func()
+}
+
+// 파일 하나에 해당하는 기록 상태
+type recordingSegment struct {
+	path        string
+	openedAt    time.Time
+	mediaStart  time.Time
+	video       media.TrackBuffer
+	audio       media.TrackBuffer
+	text        media.TrackBuffer
+	initialized bool
+	fragmentSeq uint32
+	bytes       int64
+	dropped     int   // 쓰기 대기열이 가득 차 버린 조각 수
+	err         error // 조각을 버렸을 때 (r.mu)
+
+	// writeLoop가 잠금 없이 남기는 파일 쓰기/닫기 오류
+	writeErr atomic.Pointer[error]
+
+	// 최종 자막 (세그먼트 기준 오프셋). 자막 트랙에는 앞의 textCues개가 textEnd(ms)까지 기록됨
+	subtitles []subtitles.TranscriptEntry
+	textCues  int
+	textEnd   int64
+
+	writes     chan []byte
+	writerDone chan struct{}
+}
+
+type recorderOptions struct {
+	Stream        string
+	SegmentLength time.Duration
+	AllowSegment  func() error
+	OnSegment     func(Info)
+	Transcripts   Transcripts // 세그먼트 구간의 자막 기록 세션을 메타데이터에 남긴다 (nil이면 생략)
+}
+
+func newRecorder(nextPath func() string, video, audio ingest.Stream, opts recorderOptions) (*Recorder, error) {
+	r, err := openRecorder(nextPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if r.unsubVideo, err = video.Subscribe(r.onVideo); err != nil {
+		r.Stop()
+		return nil, err
+	}
+	if r.unsubAudio, err = audio.Subscribe(r.onAudio); err != nil {
+		r.Stop()
+		return nil, err
+	}
+	return r, nil
+}
+
+// 수신을 구독하지 않는 녹화기 - 패킷/샘플을 수신 시각과 함께 직접 넣는다 (클립 추출)
+func openRecorder(nextPath func() string, opts recorderOptions) (*Recorder, error) {
+	r := &Recorder{
+		StartedAt:     time.Now(),
+		stream:        opts.Stream,
+		nextPath:      nextPath,
+		segmentLength: opts.SegmentLength,
+		allowSegment:  opts.AllowSegment,
+		onSegment:     opts.OnSegment,
+		transcripts:   opts.Transcripts,
+		channels:      2,
+	}
+
+	// 첫 파일은 바로 만들어 경로/권한 문제를 시작 시점에 알린다
+	r.mu.Lock()
+	err := r.openSegmentLocked()
+	r.mu.Unlock()
+	if err != nil && !errors.Is(err, errRecordingPaused) {
+		return nil, err
+	}
+	return r, nil
+}
+
+var errRecordingPaused = errors.New("recording paused")
+
+func (r *Recorder) openSegmentLocked() error {
+	if r.allowSegment != nil {
+		if err := r.allowSegment(); err != nil {
+			if r.paused == nil || r.paused.Error() != err.Error() {
+				log.Printf("Recording paused: %v", err)
+			}
+			r.paused = err
+			return fmt.Errorf("%w: %v", errRecordingPaused, err)
+		}
+	}
+
+	path := r.nextPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return r.segmentFailedLocked(err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return r.segmentFailedLocked(err)
+	}
+	if r.paused != nil {
+		log.Printf("Recording resumed")
+		r.paused = nil
+	}
+
+	seg := &recordingSegment{
+		path:       path,
+		openedAt:   time.Now(),
+		writes:     make(chan []byte, recordingWriteQueue),
+		writerDone: make(chan struct{}),
+	}
+	r.seg = seg
+	go r.writeLoop(seg, file)
+	log.Printf("Recording started: %s", path)
+	return nil
+}
+
+func (r *Recorder) segmentFailedLocked(err error) error {
+	if r.err == nil || r.err.Error() != err.Error() {
+		log.Printf("Recording: %v", err)
+	}
+	r.err = err
+	return err
+}
+
+// 디스크 쓰기가 RTP 수신 루프를 막지 않도록 별도 고루틴에서 기록.
+// 오류는 잠금 없이 seg.writeErr에 남기고, 세그먼트를 닫을 때 r.err로 옮긴다.
+func (r *Recorder) writeLoop(seg *recordingSegment, file *os.File) {
+	defer close(seg.writerDone)
+	for data := range seg.writes {
+		if seg.writeErr.Load() != nil {
+			continue
+		}
+		if _, err := file.Write(data); err != nil {
+			seg.writeErr.Store(&err)
+			log.Printf("Recording write failed (%s): %v", seg.path, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		seg.writeErr.CompareAndSwap(nil, &err)
+	}
+}
+
+func (r *Recorder) onVideo(pkt *rtp.Packet) {
+	r.writeVideo(pkt, time.Now())
+}
+
+func (r *Recorder) onAudio(pkt *rtp.Packet) {
+	r.writeAudio(pkt.Payload, pkt.Timestamp, time.Now())
+}
+
+func (r *Recorder) writeVideo(pkt *rtp.Packet, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	if au := r.depack.Push(pkt); au != nil {
+		r.writeAccessUnitLocked(au, now)
+	}
+}
+
+// 이미 조립된 접근 단위 (Timestamp는 90kHz RTP 타임스탬프)
+func (r *Recorder) writeAccessUnit(au *media.H264AccessUnit, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.writeAccessUnitLocked(au, now)
+	}
+}
+
+func (r *Recorder) writeAccessUnitLocked(au *media.H264AccessUnit, now time.Time) {
+	key := au.IsKey()
+
+	// 세그먼트는 키프레임에서 나눠 파일마다 단독으로 재생되게 한다
+	if key && r.seg != nil && r.seg.initialized && r.segmentLength > 0 &&
+		r.seg.video.Position() >= r.segmentLength {
+		r.closeSegmentLocked()
+	}
+	if r.seg == nil {
+		if !key || r.openSegmentLocked() != nil {
+			return
+		}
+	}
+	seg := r.seg
+	if !seg.initialized {
+		if !key || !r.initSegment(seg, au, now) {
+			return
+		}
+	}
+
+	data := au.AVCC()
+	if len(data) == 0 {
+		return
+	}
+	dts := seg.video.Base + seg.video.Clock.Extend(au.Timestamp)
+	if p := seg.video.Pending; p != nil && dts <= p.DTS {
+		dts = p.DTS + 1
+	}
+
+	// 조각은 키프레임에서 시작
+	seg.video.Push(media.TimedSample{DTS: dts, Data: data, Sync: key})
+	if key && seg.video.FragmentLength() >= recordingFragmentDuration {
+		seg.flush()
+	}
+}
+
+// Opus 패킷 하나 (ts는 48kHz RTP 타임스탬프)
+func (r *Recorder) writeAudio(payload []byte, ts uint32, now time.Time) {
+	if len(payload) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	// TOC 바이트의 stereo 플래그
+	if payload[0]&0x04 != 0 {
+		r.channels = 2
+	} else {
+		r.channels = 1
+	}
+	seg := r.seg
+	if seg == nil || !seg.initialized {
+		return
+	}
+
+	if !seg.audio.Clock.Started {
+		seg.audio.Base = int64(now.Sub(seg.mediaStart) * media.AudioClockRate / time.Second)
+	}
+	dts := seg.audio.Base + seg.audio.Clock.Extend(ts)
+	if p := seg.audio.Pending; p != nil && dts <= p.DTS {
+		return // 중복/역순 패킷
+	}
+	seg.audio.Push(media.TimedSample{DTS: dts, Data: payload, Sync: true})
+
+	if seg.audio.FragmentLength() >= recordingMaxFragmentDuration {
+		seg.flush()
+	}
+}
+
+func (r *Recorder) initSegment(seg *recordingSegment, au *media.H264AccessUnit, now time.Time) bool {
+	sps, pps := au.ParameterSets()
+	if sps == nil || pps == nil {
+		return false
+	}
+	width, height, err := media.ParseSPSResolution(sps)
+	if err != nil {
+		log.Printf("Recording: %v", err)
+		return false
+	}
+
+	seg.video.Track = &media.MP4Track{ID: 1, Kind: media.MP4TrackVideo, Timescale: media.VideoClockRate,
+		Width: width, Height: height, SPS: sps, PPS: pps}
+	seg.audio.Track = &media.MP4Track{ID: 2, Kind: media.MP4TrackAudio, Timescale: media.AudioClockRate, Channels: r.channels}
+	seg.text.Track = &media.MP4Track{ID: 3, Kind: media.MP4TrackText, Timescale: textTimescale, Width: width, Height: height}
+	seg.mediaStart = now
+	seg.initialized = true
+
+	seg.write(media.BuildMP4Init([]*media.MP4Track{seg.video.Track, seg.audio.Track, seg.text.Track}))
+	log.Printf("Recording %s: %dx%d H.264, %dch Opus", filepath.Base(seg.path), width, height, r.channels)
+	return true
+}
+
+func (seg *recordingSegment) flush() {
+	var frags []media.MP4TrackFragment
+	for _, b := range []*media.TrackBuffer{&seg.video, &seg.audio, &seg.text} {
+		if frag, ok := b.Take(); ok {
+			frags = append(frags, frag)
+		}
+	}
+	if len(frags) == 0 {
+		return
+	}
+	seg.fragmentSeq++
+	seg.write(media.BuildMP4Fragment(seg.fragmentSeq, frags))
+}
+
+// 수신 루프(잠금 안)에서 호출되므로 기다리지 않는다.
+// 디스크가 밀려 대기열이 가득 차면 조각을 버리고 세그먼트 오류로 남긴다.
+func (seg *recordingSegment) write(data []byte) {
+	select {
+	case seg.writes <- data:
+		seg.bytes += int64(len(data))
+	default:
+		if seg.dropped == 0 {
+			log.Printf("Recording write queue full (%s), dropping fragments", seg.path)
+		}
+		seg.dropped++
+		seg.err = fmt.Errorf("disk too slow: %d fragments dropped", seg.dropped)
+	}
+}
+
+// 파일 쓰기 오류가 먼저, 없으면 조각을 버린 기록
+func (seg *recordingSegment) error() error {
+	if p := seg.writeErr.Load(); p != nil {
+		return *p
+	}
+	return seg.err
+}
+
+// 현재 세그먼트의 남은 샘플을 기록하고 닫는다. 파일 마무리는 백그라운드에서 기다린다.
+func (r *Recorder) closeSegmentLocked() {
+	seg := r.seg
+	if seg == nil {
+		return
+	}
+	r.seg = nil
+	var cues []subtitles.Cue
+	if seg.initialized {
+		seg.video.Drain()
+		seg.audio.Drain()
+		seg.settleText(seg.video.Position())
+		seg.flush()
+		cues = seg.cues()
+	}
+	close(seg.writes)
+
+	r.closing.Add(1)
+	go func() {
+		defer r.closing.Done()
+		<-seg.writerDone
+
+		r.mu.Lock()
+		if err := seg.error(); err != nil {
+			r.err = err
+		}
+		info := r.segmentInfoLocked(seg)
+		info.Active = false
+		r.last = info
+		entry := r.segmentEntryLocked(seg)
+		r.mu.Unlock()
+
+		if !seg.initialized {
+			// 키프레임을 한 번도 받지 못한 빈 파일은 남기지 않음
+			os.Remove(seg.path)
+			return
+		}
+		entry.Active = false
+		if r.transcripts != nil {
+			entry.Transcripts = r.transcripts.SessionsBetween(entry.StartedAt, entry.EndedAt)
+		}
+		if len(cues) > 0 {
+			if err := writeRecordingVTT(seg.path, cues); err != nil {
+				log.Printf("Recording subtitles not saved (%s): %v", seg.path, err)
+			} else {
+				entry.Subtitles = len(cues)
+			}
+		}
+		if err := writeRecordingMeta(seg.path, entry); err != nil {
+			log.Printf("Recording metadata not saved (%s): %v", seg.path, err)
+		}
+		log.Printf("Recording saved: %s (%s, %d bytes)", seg.path, info.Duration, info.Bytes)
+		if r.onSegment != nil {
+			r.onSegment(info)
+		}
+	}()
+}
+
+// 현재 세그먼트를 닫는다. 다음 키프레임에서 새 세그먼트를 연다.
+func (r *Recorder) EndSegment() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closeSegmentLocked()
+	}
+}
+
+// 기록 중인 세그먼트 파일 경로 (없으면 "")
+func (r *Recorder) CurrentPath() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seg == nil {
+		return ""
+	}
+	return r.seg.path
+}
+
+// 기록 중인 세그먼트의 카탈로그 항목
+func (r *Recorder) ActiveEntry() (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seg == nil || r.closed {
+		return Entry{}, false
+	}
+	return r.segmentEntryLocked(r.seg), true
+}
+
+// 남은 샘플을 기록하고 파일을 닫는다
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		r.closing.Wait()
+		return r.Err()
+	}
+	r.closed = true
+	r.closeSegmentLocked()
+	unsubs := []func(){r.unsubVideo, r.unsubAudio}
+	r.mu.Unlock()
+
+	for _, unsub := range unsubs {
+		if unsub != nil {
+			unsub()
+		}
+	}
+	r.closing.Wait()
+	return r.Err()
+}
+
+// 새 세그먼트를 열지 못하고 있는 이유 (기록 중이면 nil)
+func (r *Recorder) Paused() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.paused
+}
+
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seg != nil {
+		if err := r.seg.error(); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+type Info struct {
+	File      string    `json:"file"`
+	Active    bool      `json:"active"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  string    `json:"duration"`
+	Bytes     int64     `json:"bytes"`
+	HasVideo  bool      `json:"has_video"`
+	HasAudio  bool      `json:"has_audio"`
+	Dropped   int       `json:"dropped_fragments,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// 기록 중인 세그먼트 (없으면 마지막으로 닫힌 세그먼트)
+func (r *Recorder) Info() Info {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seg == nil {
+		return r.last
+	}
+	return r.segmentInfoLocked(r.seg)
+}
+
+func (r *Recorder) segmentInfoLocked(seg *recordingSegment) Info {
+	info := Info{
+		File:      filepath.Base(seg.path),
+		Active:    !r.closed,
+		StartedAt: seg.openedAt,
+		Bytes:     seg.bytes,
+		HasVideo:  seg.video.Count > 0,
+		HasAudio:  seg.audio.Count > 0,
+		Dropped:   seg.dropped,
+	}
+	if seg.initialized {
+		info.StartedAt = seg.mediaStart
+		info.Duration = seg.video.Position().Round(time.Millisecond).String()
+	}
+	if err := seg.error(); err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+func (r *Recorder) segmentEntryLocked(seg *recordingSegment) Entry {
+	e := Entry{
+		Stream:    r.stream,
+		File:      filepath.Base(seg.path),
+		StartedAt: seg.openedAt,
+		Bytes:     seg.bytes,
+		HasVideo:  seg.video.Count > 0,
+		HasAudio:  seg.audio.Count > 0,
+		Active:    true,
+		path:      seg.path,
+	}
+	if seg.initialized {
+		d := seg.video.Position().Round(time.Millisecond)
+		e.StartedAt = seg.mediaStart
+		e.Duration = d.Seconds()
+		e.Width, e.Height = seg.video.Track.Width, seg.video.Track.Height
+	}
+	e.EndedAt = e.StartedAt.Add(time.Duration(e.Duration * float64(time.Second)))
+	e.fill()
+	return e
+}
+
+// ---------- Recording manager ----------
+
+var (
+	ErrActive   = errors.New("recording already in progress")
+	ErrInactive = errors.New("no recording in progress")
+)
+
+// 녹화기가 쓰는 자막 기록 (subtitles.TranscriptStore)
+type Transcripts interface {
+	SessionsBetween(start, end time.Time) []string
+	EntriesBetween(start, end time.Time) []subtitles.TranscriptEntry
+}
+
+// 클립을 자를 수 있는 시간 이동 버퍼 (dvr.Buffer)
+type TimeShift interface {
+	Oldest() (time.Time, bool)
+	Range(from, to time.Time) []dvr.Packet
+}
+
+type Config struct {
+	Dir          string
+	MinFreeBytes uint64 // 수동 녹화 중 남아 있어야 할 여유 공간 (0이면 확인 안 함)
+	Video, Audio ingest.Stream
+	Transcripts  Transcripts
+	TimeShift    TimeShift // nil이면 클립은 녹화 파일에서만 자른다
+}
+
+type Manager struct {
+	dir          string
+	minFreeBytes uint64
+	video, audio ingest.Stream
+	transcripts  Transcripts
+	timeShift    TimeShift
+
+	mu         sync.Mutex
+	manual     *Recorder
+	manualStop chan struct{} // 여유 공간 확인 중단
+	aborted    *Info         // 여유 공간 부족으로 멈춘 마지막 수동 녹화
+	continuous *continuousRecording
+}
+
+func NewManager(cfg Config) *Manager {
+	return &Manager{
+		dir:          cfg.Dir,
+		minFreeBytes: cfg.MinFreeBytes,
+		video:        cfg.Video,
+		audio:        cfg.Audio,
+		transcripts:  cfg.Transcripts,
+		timeShift:    cfg.TimeShift,
+	}
+}
+
+func (m *Manager) Start() (Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.manual != nil {
+		return m.manual.Info(), ErrActive
+	}
+
+	if err := m.checkFreeSpace(); err != nil {
+		return Info{}, err
+	}
+	path := uniqueRecordingPath(m.dir, recordingManualPrefix, time.Now())
+	rec, err := newRecorder(func() string { return path }, m.video, m.audio,
+		recorderOptions{Stream: recordingStreamManual, Transcripts: m.transcripts})
+	if err != nil {
+		return Info{}, err
+	}
+	m.manual = rec
+	m.aborted = nil
+	if m.minFreeBytes > 0 {
+		m.manualStop = make(chan struct{})
+		go m.watchFreeSpace(rec, m.manualStop)
+	}
+	return rec.Info(), nil
+}
+
+func (m *Manager) watchFreeSpace(rec *Recorder, stop <-chan struct{}) {
+	ticker := time.NewTicker(freeSpaceCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !m.checkManualSpace(rec) {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 여유 공간이 최소치 아래로 내려가면 수동 녹화를 오류와 함께 멈춘다. 녹화가 계속되면 true.
+func (m *Manager) checkManualSpace(rec *Recorder) bool {
+	err := m.checkFreeSpace()
+	if err == nil {
+		return true
+	}
+
+	m.mu.Lock()
+	if m.manual != rec {
+		m.mu.Unlock()
+		return false
+	}
+	m.manual = nil
+	m.manualStop = nil
+	m.mu.Unlock()
+
+	log.Printf("Recording stopped: %v", err)
+	rec.Stop()
+	info := rec.Info()
+	info.Error = err.Error()
+
+	m.mu.Lock()
+	if m.manual == nil {
+		m.aborted = &info
+	}
+	m.mu.Unlock()
+	return false
+}
+
+// 연속 녹화와 같은 여유 공간 기준 (수동 녹화는 지울 세그먼트가 없으므로 시작하지 않고, 녹화 중이면 멈춘다)
+func (m *Manager) checkFreeSpace() error {
+	if m.minFreeBytes == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	free, _, err := sysstatus.DiskSpace(m.dir)
+	if err != nil {
+		return err
+	}
+	if free < m.minFreeBytes {
+		return fmt.Errorf("free space %s below minimum %s",
+			sysstatus.FormatDiskSize(free), sysstatus.FormatDiskSize(m.minFreeBytes))
+	}
+	return nil
+}
+
+func (m *Manager) Stop() (Info, error) {
+	m.mu.Lock()
+	rec := m.manual
+	m.manual = nil
+	m.aborted = nil
+	if m.manualStop != nil {
+		close(m.manualStop)
+		m.manualStop = nil
+	}
+	m.mu.Unlock()
+
+	if rec == nil {
+		return Info{}, ErrInactive
+	}
+	err := rec.Stop()
+	return rec.Info(), err
+}
+
+// 서버 종료 시 진행 중인 수동/연속 녹화를 모두 마무리한다
+func (m *Manager) Close() error {
+	var errs []error
+	if _, err := m.Stop(); err != ErrInactive {
+		errs = append(errs, err)
+	}
+	if err := m.StopContinuous(); err != ErrInactive {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// 진행 중인 수동 녹화. 없으면 여유 공간 부족으로 멈춘 마지막 녹화 (Active=false, Error 포함).
+func (m *Manager) Status() (Info, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.manual == nil {
+		if m.aborted != nil {
+			return *m.aborted, true
+		}
+		return Info{}, false
+	}
+	return m.manual.Info(), true
+}
@@ -0,0 +1,77 @@
+package recording
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webrtc-streamer/internal/ingest"
+)
+
+// 구독만 받고 패킷은 보내지 않는 가짜 수신
+type fakeStream struct{}
+
+func (fakeStream) Subscribe(ingest.Sink) (func(), error) { return func() {}, nil }
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUniqueRecordingPath(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	want := []string{"rec-20260102-030405.mp4", "rec-20260102-030405-2.mp4", "rec-20260102-030405-3.mp4"}
+	for _, name := range want {
+		path := uniqueRecordingPath(dir, recordingManualPrefix, at)
+		if filepath.Base(path) != name {
+			t.Fatalf("uniqueRecordingPath() = %s, want %s", filepath.Base(path), name)
+		}
+		touch(t, path)
+	}
+}
+
+func TestStartChecksFreeSpace(t *testing.T) {
+	m := NewManager(Config{Dir: t.TempDir(), MinFreeBytes: math.MaxUint64})
+	if _, err := m.Start(); err == nil || !strings.Contains(err.Error(), "below minimum") {
+		t.Fatalf("Start() error = %v, want free space error", err)
+	}
+}
+
+// 녹화 중 여유 공간이 최소치 아래로 내려가면 멈추고, 상태에 이유를 남긴다
+func TestManualRecordingStopsOnLowSpace(t *testing.T) {
+	m := NewManager(Config{Dir: t.TempDir(), MinFreeBytes: 1, Video: fakeStream{}, Audio: fakeStream{}})
+	if _, err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	rec := m.manual
+	m.mu.Unlock()
+
+	if !m.checkManualSpace(rec) {
+		t.Fatal("recording stopped with enough free space")
+	}
+	m.minFreeBytes = math.MaxUint64
+	if m.checkManualSpace(rec) {
+		t.Fatal("recording kept below the free space minimum")
+	}
+
+	info, ok := m.Status()
+	if !ok || info.Active || !strings.Contains(info.Error, "below minimum") {
+		t.Errorf("Status() = %+v, %v; want the stopped recording with the error", info, ok)
+	}
+	if _, err := m.Stop(); err != ErrInactive {
+		t.Errorf("Stop() error = %v, want ErrInactive", err)
+	}
+	if _, ok := m.Status(); ok {
+		t.Error("Status() still reports the stopped recording after Stop")
+	}
+}
@@ -0,0 +1,253 @@
+package recording
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"webrtc-streamer/internal/sysstatus"
+)
+
+// ---------- Continuous recording ----------
+
+const (
+	continuousSegmentPrefix = "seg-"
+	minContinuousSegment    = 10 * time.Second
+	retentionInterval       = 30 * time.Second
+)
+
+type ContinuousConfig struct {
+	Dir           string // 비어 있으면 녹화 디렉터리의 continuous
+	SegmentLength time.Duration
+	MaxAge        time.Duration // 0이면 기간 제한 없음
+	MinFreeBytes  uint64        // 파일시스템에 항상 남겨둘 여유 공간
+}
+
+// 고정 길이 세그먼트로 계속 녹화하고, 오래된 세그먼트부터 지워 보관 정책을 지킨다
+type continuousRecording struct {
+	cfg ContinuousConfig
+	rec *Recorder
+
+	mu   sync.Mutex    // 정리 작업 직렬화
+	free atomic.Uint64 // 마지막 정리 후 여유 공간 (allowSegment가 읽는다)
+	stop chan struct{}
+}
+
+type segmentFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (m *Manager) StartContinuous(cfg ContinuousConfig) error {
+	if cfg.SegmentLength < minContinuousSegment {
+		cfg.SegmentLength = minContinuousSegment
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(m.dir, continuousDirName)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.continuous != nil {
+		return ErrActive
+	}
+
+	c := &continuousRecording{cfg: cfg, stop: make(chan struct{})}
+	// 첫 세그먼트를 열기 전에 한 번 정리해 여유 공간을 채워 둔다
+	free, err := c.prune("")
+	if err != nil {
+		return err
+	}
+	c.free.Store(free)
+	nextPath := func() string {
+		return uniqueRecordingPath(cfg.Dir, continuousSegmentPrefix, time.Now())
+	}
+	rec, err := newRecorder(nextPath, m.video, m.audio, recorderOptions{
+		Stream:        recordingStreamContinuous,
+		SegmentLength: cfg.SegmentLength,
+		AllowSegment:  c.allowSegment,
+		OnSegment:     func(Info) { c.enforce() },
+		Transcripts:   m.transcripts,
+	})
+	if err != nil {
+		return err
+	}
+	c.rec = rec
+	m.continuous = c
+	go c.retentionLoop()
+
+	log.Printf("Continuous recording: %s segments in %s (max age %s, min free %s)",
+		cfg.SegmentLength, cfg.Dir, cfg.MaxAge, sysstatus.FormatDiskSize(cfg.MinFreeBytes))
+	return nil
+}
+
+// 새 세그먼트를 열기 전 확인. RTP 수신 경로(녹화기 잠금 안)에서 키프레임마다 불릴 수 있으므로
+// 디스크를 건드리지 않고 정리 루프가 남긴 여유 공간만 본다. 부족하면 녹화를 멈춘다.
+func (c *continuousRecording) allowSegment() error {
+	if free := c.free.Load(); free < c.cfg.MinFreeBytes {
+		return fmt.Errorf("free space %s below minimum %s",
+			sysstatus.FormatDiskSize(free), sysstatus.FormatDiskSize(c.cfg.MinFreeBytes))
+	}
+	return nil
+}
+
+func (c *continuousRecording) retentionLoop() {
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.enforce()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// 현재 세그먼트를 마무리하고 연속 녹화를 멈춘다
+func (m *Manager) StopContinuous() error {
+	m.mu.Lock()
+	c := m.continuous
+	m.continuous = nil
+	m.mu.Unlock()
+
+	if c == nil {
+		return ErrInactive
+	}
+	close(c.stop)
+	return c.rec.Stop()
+}
+
+// 녹화 중인 세그먼트를 제외하고 정리한 뒤, 여유 공간이 여전히 부족하면 현재 세그먼트를 닫는다.
+// 세그먼트가 닫힐 때와 retentionLoop에서만 호출된다 (RTP 수신 경로 밖).
+func (c *continuousRecording) enforce() {
+	current := c.rec.CurrentPath()
+	free, err := c.prune(current)
+	if err != nil {
+		log.Printf("Recording retention: %v", err)
+		return
+	}
+	c.free.Store(free)
+	if free < c.cfg.MinFreeBytes && current != "" {
+		log.Printf("Recording retention: free space %s below minimum, closing current segment",
+			sysstatus.FormatDiskSize(free))
+		c.rec.EndSegment()
+	}
+}
+
+// 기간이 지난 세그먼트를 지우고, 여유 공간이 최소치보다 적으면 오래된 것부터 지운다.
+// 남은 여유 공간을 반환한다.
+func (c *continuousRecording) prune(exclude string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	segments, err := c.segments()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var kept []segmentFile
+	for _, s := range segments {
+		if s.path == exclude {
+			continue
+		}
+		if c.cfg.MaxAge > 0 && now.Sub(s.modTime) > c.cfg.MaxAge {
+			c.remove(s, "expired")
+			continue
+		}
+		kept = append(kept, s)
+	}
+
+	free, _, err := sysstatus.DiskSpace(c.cfg.Dir)
+	for err == nil && free < c.cfg.MinFreeBytes && len(kept) > 0 {
+		c.remove(kept[0], "low disk space")
+		kept = kept[1:]
+		free, _, err = sysstatus.DiskSpace(c.cfg.Dir)
+	}
+	return free, err
+}
+
+func (c *continuousRecording) remove(s segmentFile, reason string) {
+	if err := removeRecordingFiles(s.path); err != nil {
+		log.Printf("Recording retention: %v", err)
+		return
+	}
+	log.Printf("Recording retention: removed %s (%s, %d bytes)", filepath.Base(s.path), reason, s.size)
+}
+
+// 세그먼트 파일 목록 (오래된 것부터, 파일명의 시각 순)
+func (c *continuousRecording) segments() ([]segmentFile, error) {
+	entries, err := os.ReadDir(c.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []segmentFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, continuousSegmentPrefix) || filepath.Ext(name) != ".mp4" {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, segmentFile{path: filepath.Join(c.cfg.Dir, name), size: fi.Size(), modTime: fi.ModTime()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].path < out[j].path })
+	return out, nil
+}
+
+type ContinuousStatus struct {
+	Enabled        bool    `json:"enabled"`
+	SegmentSeconds int     `json:"segment_seconds,omitempty"`
+	MaxAgeHours    float64 `json:"max_age_hours,omitempty"`
+	MinFreeBytes   uint64  `json:"min_free_bytes,omitempty"`
+	Paused         string  `json:"paused,omitempty"`
+	Current        *Info   `json:"current,omitempty"`
+	Segments       int     `json:"segments"`
+	Bytes          int64   `json:"bytes"`
+	FreeBytes      uint64  `json:"free_bytes"`
+}
+
+func (m *Manager) ContinuousStatus() ContinuousStatus {
+	m.mu.Lock()
+	c := m.continuous
+	m.mu.Unlock()
+	if c == nil {
+		return ContinuousStatus{}
+	}
+
+	status := ContinuousStatus{
+		Enabled:        true,
+		SegmentSeconds: int(c.cfg.SegmentLength / time.Second),
+		MaxAgeHours:    c.cfg.MaxAge.Hours(),
+		MinFreeBytes:   c.cfg.MinFreeBytes,
+	}
+	if err := c.rec.Paused(); err != nil {
+		status.Paused = err.Error()
+	}
+	if c.rec.CurrentPath() != "" {
+		info := c.rec.Info()
+		status.Current = &info
+	}
+	c.mu.Lock()
+	segments, _ := c.segments()
+	c.mu.Unlock()
+	for _, s := range segments {
+		status.Segments++
+		status.Bytes += s.size
+	}
+	status.FreeBytes, _, _ = sysstatus.DiskSpace(c.cfg.Dir)
+	return status
+}
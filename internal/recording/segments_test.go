@@ -0,0 +1,115 @@
+package recording
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxAge  time.Duration
+		minFree uint64
+		exclude string
+		want    []string // 남는 세그먼트
+	}{
+		{"기간 제한 없음", 0, 0, "", []string{"seg-1", "seg-2", "seg-3"}},
+		{"오래된 세그먼트 삭제", 2 * time.Hour, 0, "", []string{"seg-2", "seg-3"}},
+		{"녹화 중인 세그먼트는 유지", time.Minute, 0, "seg-1", []string{"seg-1", "seg-3"}},
+		{"여유 공간이 부족하면 모두 삭제", 0, math.MaxUint64, "seg-3", []string{"seg-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			now := time.Now()
+			ages := map[string]time.Duration{"seg-1": 3 * time.Hour, "seg-2": time.Hour, "seg-3": 0}
+			for name, age := range ages {
+				path := filepath.Join(dir, name+".mp4")
+				touch(t, path)
+				touch(t, recordingSidecar(path, recordingMetaExt))
+				if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			touch(t, filepath.Join(dir, "other.mp4")) // 세그먼트가 아닌 파일은 건드리지 않는다
+
+			c := &continuousRecording{cfg: ContinuousConfig{Dir: dir, MaxAge: tt.maxAge, MinFreeBytes: tt.minFree}}
+			exclude := ""
+			if tt.exclude != "" {
+				exclude = filepath.Join(dir, tt.exclude+".mp4")
+			}
+			if _, err := c.prune(exclude); err != nil {
+				t.Fatal(err)
+			}
+
+			segments, err := c.segments()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range segments {
+				got = append(got, filepath.Base(s.path[:len(s.path)-len(".mp4")]))
+				if _, err := os.Stat(recordingSidecar(s.path, recordingMetaExt)); err != nil {
+					t.Errorf("%s lost its sidecar", s.path)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "other.mp4")); err != nil {
+				t.Error("removed a file that is not a segment")
+			}
+			if n := len(mustGlob(t, filepath.Join(dir, "*.json"))); n != len(tt.want) {
+				t.Errorf("%d sidecars left, want %d", n, len(tt.want))
+			}
+		})
+	}
+}
+
+func mustGlob(t *testing.T, pattern string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+// allowSegment는 디스크를 건드리지 않고 정리 루프가 남긴 여유 공간만 본다
+func TestAllowSegmentUsesCachedFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "seg-1.mp4")
+	touch(t, old)
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+	c := &continuousRecording{cfg: ContinuousConfig{Dir: dir, MaxAge: time.Minute, MinFreeBytes: 100}, rec: &Recorder{}}
+
+	c.free.Store(50)
+	if err := c.allowSegment(); err == nil {
+		t.Error("allowSegment() allowed a segment below the free space minimum")
+	}
+	c.free.Store(200)
+	if err := c.allowSegment(); err != nil {
+		t.Errorf("allowSegment() = %v", err)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Fatal("allowSegment removed an expired segment")
+	}
+
+	// 정리는 enforce에서 하고 여유 공간을 새로 채운다
+	c.free.Store(0)
+	c.cfg.MinFreeBytes = 0
+	c.enforce()
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("enforce kept an expired segment")
+	}
+	if c.free.Load() == 0 {
+		t.Error("enforce did not refresh the free space")
+	}
+}
@@ -0,0 +1,323 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/ingest"
+)
+
+// ---------- WebRTC sessions ----------
+
+var (
+	ErrBusy        = errors.New("stream already in progress")
+	ErrClosed      = errors.New("session manager closed")
+	ErrNegotiation = errors.New("WebRTC failed")
+)
+
+// 외부 접속에 쓰는 STUN 서버
+var DefaultICEServers = []webrtc.ICEServer{
+	{URLs: []string{"stun:stun.l.google.com:19302"}},
+}
+
+type Config struct {
+	Network    *Network
+	ICEServers []webrtc.ICEServer // localhost/내부망 접속에는 쓰지 않는다
+	Video      ingest.Stream
+	Audio      ingest.Stream
+	DVR        *dvr.Buffer // nil이면 시간 이동 재생을 하지 않음
+}
+
+// WebRTC 송출 세션을 하나씩 받는다
+type Manager struct {
+	network    *Network
+	iceServers []webrtc.ICEServer
+	video      ingest.Stream
+	audio      ingest.Stream
+	dvr        *dvr.Buffer
+
+	mu         sync.Mutex // iceServers 포함
+	inProgress bool
+	closed     bool
+	active     *Stream
+
+	// 종료 시 닫을 PeerConnection (정리가 끝나면 빠진다)
+	peers   map[*webrtc.PeerConnection]bool
+	peersWG sync.WaitGroup
+}
+
+func NewManager(cfg Config) *Manager {
+	return &Manager{
+		network:    cfg.Network,
+		iceServers: cfg.ICEServers,
+		video:      cfg.Video,
+		audio:      cfg.Audio,
+		dvr:        cfg.DVR,
+		peers:      make(map[*webrtc.PeerConnection]bool),
+	}
+}
+
+type StartOptions struct {
+	Localhost bool          // localhost/내부망 접속이면 STUN 없이 직접 연결
+	Offset    time.Duration // 0이면 라이브, 아니면 그만큼 이전부터 재생
+
+	// 세션이 시작되고 연결 상태를 보기 전에 호출된다
+	OnStart func(s *Stream)
+	// 연결이 끝나 세션을 정리할 때 한 번 호출된다
+	OnEnd func()
+	// 시간 이동 재생이 라이브에 따라잡아 라이브로 돌아왔을 때
+	OnLive func(s *Stream)
+}
+
+// offer에 대한 answer를 만들고 송출을 시작한다.
+// 이미 진행 중인 세션이 있으면 ErrBusy, 협상 실패는 ErrNegotiation으로 감싸서 반환한다.
+func (m *Manager) Start(offer webrtc.SessionDescription, opts StartOptions) (*webrtc.SessionDescription, *Stream, error) {
+	m.mu.Lock()
+	switch {
+	case m.closed:
+		m.mu.Unlock()
+		return nil, nil, ErrClosed
+	case m.inProgress:
+		m.mu.Unlock()
+		log.Printf("Stream blocked - already in progress")
+		return nil, nil, ErrBusy
+	}
+	// 협상하는 동안 다른 요청이 끼어들지 않도록 미리 표시하고, 실패하면 지운다
+	m.inProgress = true
+	m.mu.Unlock()
+
+	pc, videoTrack, audioTrack, err := m.newPeerConnection(&offer, opts.Localhost)
+	if err != nil {
+		m.Reset()
+		return nil, nil, fmt.Errorf("%w: %v", ErrNegotiation, err)
+	}
+
+	// RTP 수신 구독 - 패킷을 WebRTC 트랙으로 전달
+	stream := newStream(m, videoTrack, audioTrack)
+	stream.onLive = opts.OnLive
+	if err := stream.Seek(opts.Offset); err != nil {
+		stream.Close()
+		pc.Close()
+		m.Reset()
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.inProgress = false
+		m.mu.Unlock()
+		stream.Close()
+		pc.Close()
+		return nil, nil, ErrClosed
+	}
+	m.active = stream
+	m.mu.Unlock()
+
+	if opts.OnStart != nil {
+		opts.OnStart(stream)
+	}
+	// 즉시 정리를 위한 연결 모니터링
+	m.watch(pc, func() {
+		// 연결 전에 보낸 패킷은 버려지므로 연결되면 키프레임부터 다시 시작
+		if err := stream.Seek(opts.Offset); err != nil {
+			log.Printf("Stream seek failed: %v", err)
+		}
+	}, func() {
+		m.mu.Lock()
+		m.inProgress = false
+		m.mu.Unlock()
+		stream.Close()
+		pc.Close()
+		if opts.OnEnd != nil {
+			opts.OnEnd()
+		}
+		log.Printf("Stream resources cleaned up")
+	})
+
+	return pc.LocalDescription(), stream, nil
+}
+
+// 이후 세션부터 쓸 ICE 서버를 바꾼다 (진행 중인 세션은 그대로)
+func (m *Manager) SetICEServers(servers []webrtc.ICEServer) {
+	m.mu.Lock()
+	m.iceServers = servers
+	m.mu.Unlock()
+}
+
+// 진행 중인 세션이 있다고 기록되어 있는지
+func (m *Manager) Busy() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inProgress
+}
+
+// 정리되지 않은 세션 표시를 지운다 (클라이언트의 /reset)
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	m.inProgress = false
+	m.mu.Unlock()
+}
+
+// 현재 송출 중인 세션 (없으면 nil)
+func (m *Manager) Current() *Stream {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+func (m *Manager) release(s *Stream) {
+	m.mu.Lock()
+	if m.active == s {
+		m.active = nil
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) newPeerConnection(offer *webrtc.SessionDescription, isLocalhost bool) (*webrtc.PeerConnection, *webrtc.TrackLocalStaticRTP, *webrtc.TrackLocalStaticRTP, error) {
+	log.Printf("Initializing WebRTC session (localhost: %t)", isLocalhost)
+
+	// localhost/내부망 접속인 경우 STUN 서버 없이 직접 연결
+	var iceServers []webrtc.ICEServer
+	if !isLocalhost {
+		m.mu.Lock()
+		iceServers = m.iceServers
+		m.mu.Unlock()
+	}
+
+	// localhost 환경에 최적화된 설정
+	config := webrtc.Configuration{
+		ICEServers:         iceServers,
+		ICETransportPolicy: webrtc.ICETransportPolicyAll,
+		BundlePolicy:       webrtc.BundlePolicyMaxBundle,
+	}
+
+	api := webrtc.NewAPI(webrtc.WithSettingEngine(m.network.settingsFor(isLocalhost)))
+	pc, err := api.NewPeerConnection(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("NewPeerConnection: %w", err)
+	}
+
+	// 트랙 생성 및 추가
+	videoTrack, err := webrtc.NewTrackLocalStaticRTP(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264}, "video", "pion")
+	if err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("video track: %w", err)
+	}
+
+	audioTrack, err := webrtc.NewTrackLocalStaticRTP(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, "audio", "pion")
+	if err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("audio track: %w", err)
+	}
+
+	if _, err = pc.AddTrack(videoTrack); err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("add video track: %w", err)
+	}
+	if _, err = pc.AddTrack(audioTrack); err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("add audio track: %w", err)
+	}
+
+	// SDP 처리
+	if err := pc.SetRemoteDescription(*offer); err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("SetRemoteDescription: %w", err)
+	}
+
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("CreateAnswer: %w", err)
+	}
+	if err := pc.SetLocalDescription(answer); err != nil {
+		pc.Close()
+		return nil, nil, nil, fmt.Errorf("SetLocalDescription: %w", err)
+	}
+
+	// ICE 수집 완료 대기
+	<-webrtc.GatheringCompletePromise(pc)
+	return pc, videoTrack, audioTrack, nil
+}
+
+func (m *Manager) watch(pc *webrtc.PeerConnection, onConnected, cleanup func()) {
+	var once sync.Once
+
+	m.mu.Lock()
+	m.peers[pc] = true
+	m.peersWG.Add(1)
+	m.mu.Unlock()
+
+	// 연결 상태와 ICE 상태 콜백이 서로 다른 고루틴에서 불리므로 한 번만 정리한다
+	doCleanup := func(reason string) {
+		once.Do(func() {
+			log.Printf("Connection ended: %s", reason)
+			cleanup()
+
+			m.mu.Lock()
+			delete(m.peers, pc)
+			m.mu.Unlock()
+			m.peersWG.Done()
+		})
+	}
+
+	pc.OnConnectionStateChange(func(s webrtc.PeerConnectionState) {
+		log.Printf("Connection: %s", s.String())
+		switch s {
+		case webrtc.PeerConnectionStateConnected:
+			onConnected()
+		case webrtc.PeerConnectionStateFailed,
+			webrtc.PeerConnectionStateClosed,
+			webrtc.PeerConnectionStateDisconnected:
+			doCleanup(s.String())
+		}
+	})
+
+	pc.OnICEConnectionStateChange(func(s webrtc.ICEConnectionState) {
+		log.Printf("ICE: %s", s.String())
+		switch s {
+		case webrtc.ICEConnectionStateFailed,
+			webrtc.ICEConnectionStateClosed,
+			webrtc.ICEConnectionStateDisconnected:
+			doCleanup(s.String())
+		}
+	})
+}
+
+// 새 세션을 더 받지 않고, 모든 PeerConnection을 닫아 세션 정리가 끝날 때까지 기다린다
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	list := make([]*webrtc.PeerConnection, 0, len(m.peers))
+	for pc := range m.peers {
+		list = append(list, pc)
+	}
+	m.mu.Unlock()
+
+	for _, pc := range list {
+		if err := pc.Close(); err != nil {
+			log.Printf("PeerConnection close: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.peersWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/ingest"
+)
+
+// 구독만 받고 패킷은 보내지 않는 가짜 수신
+type fakeStream struct{}
+
+func (fakeStream) Subscribe(ingest.Sink) (func(), error) { return func() {}, nil }
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	network, err := NewNetwork(NetworkConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(Config{Network: network, Video: fakeStream{}, Audio: fakeStream{}})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		m.Close(ctx)
+	})
+	return m
+}
+
+// 브라우저처럼 영상/오디오를 받기만 하는 offer
+func newTestOffer(t *testing.T) webrtc.SessionDescription {
+	t.Helper()
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	for _, kind := range []webrtc.RTPCodecType{webrtc.RTPCodecTypeVideo, webrtc.RTPCodecTypeAudio} {
+		if _, err := pc.AddTransceiverFromKind(kind, webrtc.RTPTransceiverInit{Direction: webrtc.RTPTransceiverDirectionRecvonly}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pc.SetLocalDescription(offer); err != nil {
+		t.Fatal(err)
+	}
+	return offer
+}
+
+// 동시에 들어온 요청 중 하나만 세션을 시작하고 나머지는 ErrBusy
+func TestStartAllowsOneConcurrentSession(t *testing.T) {
+	m := newTestManager(t)
+	const n = 4
+	offers := make([]webrtc.SessionDescription, n)
+	for i := range offers {
+		offers[i] = newTestOffer(t)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range offers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, errs[i] = m.Start(offers[i], StartOptions{Localhost: true})
+		}()
+	}
+	wg.Wait()
+
+	started, busy := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			started++
+		case errors.Is(err, ErrBusy):
+			busy++
+		default:
+			t.Errorf("Start: %v", err)
+		}
+	}
+	if started != 1 || busy != n-1 {
+		t.Errorf("started=%d busy=%d, want 1 and %d", started, busy, n-1)
+	}
+}
+
+// 협상에 실패하면 진행 중 표시를 지워 다음 요청을 받는다
+func TestStartClearsInProgressOnFailure(t *testing.T) {
+	m := newTestManager(t)
+	_, _, err := m.Start(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "invalid"}, StartOptions{Localhost: true})
+	if !errors.Is(err, ErrNegotiation) {
+		t.Fatalf("Start(invalid) = %v, want ErrNegotiation", err)
+	}
+	if m.Busy() {
+		t.Fatal("Busy after failed Start")
+	}
+	if _, _, err := m.Start(newTestOffer(t), StartOptions{Localhost: true}); err != nil {
+		t.Fatalf("Start after failure: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// ---------- WebRTC network ----------
+
+type NetworkConfig struct {
+	BindIP    string
+	UDPPort   int      // 0이면 세션마다 임시 포트
+	TCPPort   int      // 0이면 ICE-TCP를 쓰지 않음
+	PublicIPs []string // NAT 1:1 외부 주소
+}
+
+// 모든 세션이 공유하는 ICE 설정 (단일 포트 mux, NAT 1:1 주소)
+type Network struct {
+	settings  webrtc.SettingEngine
+	udpMux    bool
+	listeners []io.Closer // 종료 시 닫는다
+}
+
+// UDPPort / TCPPort가 설정되면 모든 세션의 ICE 트래픽을
+// UDP 포트 하나(UDPMux)와 TCP 포트 하나(ICE-TCP)로 다중화한다
+func NewNetwork(cfg NetworkConfig) (*Network, error) {
+	n := &Network{}
+	bindIP := net.ParseIP(cfg.BindIP)
+
+	if cfg.UDPPort > 0 {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: bindIP, Port: cfg.UDPPort})
+		if err != nil {
+			return nil, fmt.Errorf("WebRTC UDP port %d: %w", cfg.UDPPort, err)
+		}
+		n.settings.SetICEUDPMux(webrtc.NewICEUDPMux(nil, conn))
+		n.udpMux = true
+		n.listeners = append(n.listeners, conn)
+		log.Printf("WebRTC UDP mux listening on %s", conn.LocalAddr())
+	}
+
+	if cfg.TCPPort > 0 {
+		ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: bindIP, Port: cfg.TCPPort})
+		if err != nil {
+			n.Close()
+			return nil, fmt.Errorf("WebRTC TCP port %d: %w", cfg.TCPPort, err)
+		}
+		n.settings.SetICETCPMux(webrtc.NewICETCPMux(nil, ln, 8))
+		n.listeners = append(n.listeners, ln)
+		n.settings.SetNetworkTypes([]webrtc.NetworkType{
+			webrtc.NetworkTypeUDP4, webrtc.NetworkTypeUDP6,
+			webrtc.NetworkTypeTCP4, webrtc.NetworkTypeTCP6,
+		})
+		log.Printf("WebRTC ICE-TCP listening on %s", ln.Addr())
+	}
+
+	// NAT/포트 포워딩 뒤에서는 외부 주소를 host 후보로 알린다
+	if len(cfg.PublicIPs) > 0 {
+		n.settings.SetNAT1To1IPs(cfg.PublicIPs, webrtc.ICECandidateTypeHost)
+	}
+	return n, nil
+}
+
+// 세션용 설정 복사본. localhost/내부망 접속이면 loopback 후보를 포함한다.
+func (n *Network) settingsFor(isLocalhost bool) webrtc.SettingEngine {
+	s := n.settings
+	if isLocalhost {
+		// 로컬 연결용 포트 범위 제한 (단일 포트 mux를 쓰면 불필요)
+		if !n.udpMux {
+			s.SetEphemeralUDPPortRange(50000, 50100)
+		}
+		// 네트워크 인터페이스 필터링
+		s.SetIncludeLoopbackCandidate(true)
+	}
+	return s
+}
+
+func (n *Network) Close() {
+	for _, l := range n.listeners {
+		l.Close()
+	}
+}
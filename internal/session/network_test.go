@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 비어 있는 포트 (닫은 뒤 바로 다시 쓴다)
+func freePort(t *testing.T, network string) int {
+	t.Helper()
+	switch network {
+	case "udp":
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.LocalAddr().(*net.UDPAddr).Port
+	default:
+		ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		return ln.Addr().(*net.TCPAddr).Port
+	}
+}
+
+// 단일 포트 mux를 쓰면 모든 세션의 후보가 같은 UDP/TCP 포트를 알린다
+func TestNetworkMuxCandidates(t *testing.T) {
+	udpPort, tcpPort := freePort(t, "udp"), freePort(t, "tcp")
+	network, err := NewNetwork(NetworkConfig{BindIP: "127.0.0.1", UDPPort: udpPort, TCPPort: tcpPort, PublicIPs: []string{"203.0.113.7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(network.Close)
+
+	if _, err := NewNetwork(NetworkConfig{BindIP: "127.0.0.1", UDPPort: udpPort}); err == nil {
+		t.Error("NewNetwork() on a port in use succeeded")
+	}
+
+	m := NewManager(Config{Network: network, Video: fakeStream{}, Audio: fakeStream{}})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		m.Close(ctx)
+	})
+	answer, _, err := m.Start(newTestOffer(t), StartOptions{Localhost: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		fmt.Sprintf(" udp %%d 203.0.113.7 %d typ host", udpPort),
+		fmt.Sprintf(" tcp %%d 203.0.113.7 %d typ host tcptype passive", tcpPort),
+	} {
+		found := false
+		for _, line := range strings.Split(answer.SDP, "\r\n") {
+			var priority int
+			if _, err := fmt.Sscanf(candidateTail(line), want, &priority); err == nil {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("answer has no candidate like %q:\n%s", want, answer.SDP)
+		}
+	}
+}
+
+// "a=candidate:<foundation> <component> udp ..."에서 프로토콜부터
+func candidateTail(line string) string {
+	if !strings.HasPrefix(line, "a=candidate:") {
+		return ""
+	}
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return ""
+	}
+	return " " + fields[2]
+}
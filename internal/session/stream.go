@@ -0,0 +1,155 @@
+package session
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+)
+
+// ---------- Stream session ----------
+
+var (
+	ErrDVRDisabled  = errors.New("time-shift buffer is disabled")
+	ErrStreamClosed = errors.New("stream session closed")
+	ErrNoStream     = errors.New("no active stream session")
+)
+
+// WebRTC 세션 하나의 송출 - 라이브 수신을 그대로 보내거나 시간 이동 버퍼에서 재생한다
+type Stream struct {
+	m            *Manager
+	video, audio *dvr.Rewriter
+
+	onLive func(*Stream) // 시간 이동 재생이 라이브에 따라잡아 라이브로 돌아왔을 때
+
+	mu     sync.Mutex
+	closed bool
+	unsubs []func()
+	player *dvr.Player
+	offset time.Duration
+}
+
+// WebRTC 송출 상태 변경
+type StreamEvent struct {
+	Event string `json:"event"` // started | stopped | seek | live
+	*Status
+}
+
+type Status struct {
+	Mode   string  `json:"mode"` // live | timeshift
+	Offset float64 `json:"offset"`
+	Delay  float64 `json:"delay"` // 라이브 대비 실제 지연 (초)
+}
+
+func newStream(m *Manager, videoTrack, audioTrack *webrtc.TrackLocalStaticRTP) *Stream {
+	return &Stream{
+		m:     m,
+		video: dvr.NewRewriter(writeRTPTo(videoTrack), media.VideoClockRate/30, true),
+		audio: dvr.NewRewriter(writeRTPTo(audioTrack), media.AudioClockRate/50, false),
+	}
+}
+
+// 수신한 RTP 패킷을 트랙으로 전달하는 sink
+func writeRTPTo(track *webrtc.TrackLocalStaticRTP) ingest.Sink {
+	return func(pkt *rtp.Packet) {
+		track.WriteRTP(pkt)
+	}
+}
+
+// offset이 0이면 라이브, 아니면 그만큼 이전 시점의 키프레임부터 재생
+func (s *Stream) Seek(offset time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seekLocked(offset)
+}
+
+func (s *Stream) seekLocked(offset time.Duration) error {
+	if s.closed {
+		return ErrStreamClosed
+	}
+	if offset > 0 && s.m.dvr == nil {
+		return ErrDVRDisabled
+	}
+
+	s.stopLocked()
+	s.video.Discontinuity()
+	s.audio.Discontinuity()
+
+	if offset <= 0 {
+		offset = 0
+		unsubVideo, err := s.m.video.Subscribe(s.video.Write)
+		if err != nil {
+			return err
+		}
+		unsubAudio, err := s.m.audio.Subscribe(s.audio.Write)
+		if err != nil {
+			unsubVideo()
+			return err
+		}
+		s.unsubs = []func(){unsubVideo, unsubAudio}
+	} else {
+		player, err := s.m.dvr.Play(time.Now().Add(-offset), s.video, s.audio, s.caughtUp)
+		if err != nil {
+			return err
+		}
+		s.player = player
+	}
+	s.offset = offset
+	return nil
+}
+
+// 재생이 가장 최근 GOP에 닿으면 버퍼를 거치지 않고 라이브 수신으로 바꾼다
+func (s *Stream) caughtUp(p *dvr.Player) {
+	s.mu.Lock()
+	if s.closed || s.player != p {
+		s.mu.Unlock()
+		return
+	}
+	err := s.seekLocked(0)
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("Stream return to live failed: %v", err)
+		return
+	}
+	log.Printf("Stream caught up - back to live")
+	if s.onLive != nil {
+		s.onLive(s)
+	}
+}
+
+func (s *Stream) stopLocked() {
+	for _, unsub := range s.unsubs {
+		unsub()
+	}
+	s.unsubs = nil
+	if s.player != nil {
+		s.player.Stop()
+		s.player = nil
+	}
+}
+
+func (s *Stream) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.stopLocked()
+	s.mu.Unlock()
+	s.m.release(s)
+}
+
+func (s *Stream) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := Status{Mode: "live", Offset: s.offset.Seconds()}
+	if s.player != nil {
+		status.Mode = "timeshift"
+		status.Delay = media.RoundSeconds(s.player.Delay())
+	}
+	return status
+}
@@ -0,0 +1,154 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/media"
+)
+
+// ---------- Snapshot ----------
+
+// JPEG 변환 명령의 최대 실행 시간
+const snapshotDecodeTimeout = 5 * time.Second
+
+var ErrNoKeyframe = errors.New("no keyframe received yet")
+
+type Keyframe struct {
+	AU            *media.H264AccessUnit
+	SPS, PPS      []byte
+	Width, Height int
+	CapturedAt    time.Time
+	seq           uint64
+}
+
+// SPS/PPS를 앞에 붙인 Annex-B (단독으로 디코딩 가능)
+func (k *Keyframe) AnnexB() []byte {
+	au := &media.H264AccessUnit{Timestamp: k.AU.Timestamp}
+	if sps, pps := k.AU.ParameterSets(); sps == nil || pps == nil {
+		au.NALUs = append(au.NALUs, k.SPS, k.PPS)
+	}
+	au.NALUs = append(au.NALUs, k.AU.NALUs...)
+	return au.AnnexB()
+}
+
+// 프레임 하나짜리 MP4
+func (k *Keyframe) MP4() []byte {
+	track := &media.MP4Track{ID: 1, Kind: media.MP4TrackVideo, Timescale: media.VideoClockRate,
+		Width: k.Width, Height: k.Height, SPS: k.SPS, PPS: k.PPS}
+	frag := media.MP4TrackFragment{Track: track, Samples: []media.MP4Sample{
+		{Data: k.AU.AVCC(), Duration: media.VideoClockRate / 30, Sync: true},
+	}}
+	return append(media.BuildMP4Init([]*media.MP4Track{track}), media.BuildMP4Fragment(1, []media.MP4TrackFragment{frag})...)
+}
+
+// 영상 수신에서 마지막으로 완성된 IDR 프레임을 보관한다
+type KeyframeCache struct {
+	source  ingest.Stream
+	decoder []string // JPEG 변환 명령 (stdin: Annex-B, stdout: JPEG)
+
+	mu         sync.Mutex
+	subscribed bool
+	depack     media.H264Depacketizer
+	sps, pps   []byte
+	latest     *Keyframe
+	seq        uint64
+
+	jpegMu  sync.Mutex // 변환 명령은 한 번에 하나만 실행
+	jpegSeq uint64
+	jpeg    []byte
+}
+
+func NewKeyframeCache(source ingest.Stream, decoderCommand string) *KeyframeCache {
+	c := &KeyframeCache{source: source, decoder: strings.Fields(decoderCommand)}
+	c.subscribe()
+	return c
+}
+
+// 수신이 아직 시작되지 않았으면 요청 때 다시 시도
+func (c *KeyframeCache) subscribe() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribed {
+		return nil
+	}
+	if _, err := c.source.Subscribe(c.onVideo); err != nil {
+		return err
+	}
+	c.subscribed = true
+	return nil
+}
+
+func (c *KeyframeCache) onVideo(pkt *rtp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	au := c.depack.Push(pkt)
+	if au == nil || !au.IsKey() {
+		return
+	}
+	if sps, pps := au.ParameterSets(); sps != nil && pps != nil {
+		c.sps, c.pps = sps, pps
+	}
+	if c.sps == nil || c.pps == nil {
+		return
+	}
+	width, height, err := media.ParseSPSResolution(c.sps)
+	if err != nil {
+		return
+	}
+	c.seq++
+	c.latest = &Keyframe{AU: au, SPS: c.sps, PPS: c.pps, Width: width, Height: height,
+		CapturedAt: time.Now(), seq: c.seq}
+}
+
+func (c *KeyframeCache) Latest() (*Keyframe, error) {
+	if err := c.subscribe(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latest == nil {
+		return nil, ErrNoKeyframe
+	}
+	return c.latest, nil
+}
+
+func (c *KeyframeCache) CanDecode() bool {
+	return len(c.decoder) > 0
+}
+
+// 설정된 변환 명령으로 JPEG를 만든다. 같은 키프레임은 다시 변환하지 않는다.
+func (c *KeyframeCache) JPEG(ctx context.Context, k *Keyframe) ([]byte, error) {
+	c.jpegMu.Lock()
+	defer c.jpegMu.Unlock()
+	if c.jpeg != nil && c.jpegSeq == k.seq {
+		return c.jpeg, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotDecodeTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.decoder[0], c.decoder[1:]...)
+	cmd.Stdin = bytes.NewReader(k.AnnexB())
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %v: %s", c.decoder[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%s produced no output", c.decoder[0])
+	}
+
+	c.jpeg, c.jpegSeq = stdout.Bytes(), k.seq
+	return c.jpeg, nil
+}
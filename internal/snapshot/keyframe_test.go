@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"webrtc-streamer/internal/media"
+	"webrtc-streamer/internal/media/mediatest"
+)
+
+func TestKeyframeCacheLatest(t *testing.T) {
+	video := &mediatest.Stream{}
+	c := NewKeyframeCache(video, "")
+	if _, err := c.Latest(); !errors.Is(err, ErrNoKeyframe) {
+		t.Fatalf("Latest() before a keyframe = %v, want ErrNoKeyframe", err)
+	}
+
+	gen := &mediatest.H264{GOP: 3}
+	for i := 0; i < 5; i++ { // 키프레임 0, 3
+		pkts, _ := gen.Next()
+		video.Send(pkts...)
+	}
+	k, err := c.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.AU.Timestamp != 3*mediatest.FrameTicks || !k.AU.IsKey() {
+		t.Errorf("Latest() timestamp = %d, want the second keyframe", k.AU.Timestamp)
+	}
+	if k.Width != mediatest.Width || k.Height != mediatest.Height {
+		t.Errorf("resolution = %dx%d", k.Width, k.Height)
+	}
+
+	annexB := k.AnnexB()
+	start := []byte{0, 0, 0, 1}
+	if !bytes.HasPrefix(annexB, append(start, mediatest.SPS...)) || bytes.Count(annexB, start) != 3 {
+		t.Errorf("AnnexB() = % x, want SPS, PPS and the IDR slice", annexB)
+	}
+
+	// 프레임 하나짜리 MP4는 다시 읽을 수 있어야 한다
+	path := filepath.Join(t.TempDir(), "snapshot.mp4")
+	if err := os.WriteFile(path, k.MP4(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var samples []media.MP4ReadSample
+	err = media.ReadMP4Fragments(path, func(s []media.MP4ReadSample) bool {
+		samples = append(samples, s...)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || !samples[0].Sync || samples[0].Track.Width != mediatest.Width ||
+		!bytes.Equal(samples[0].Track.SPS, mediatest.SPS) {
+		t.Errorf("MP4() samples = %+v, want one keyframe", samples)
+	}
+}
+
+// 표준 입력을 버리고 매번 다른 값을 출력하는 변환 명령
+func writeDecoder(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "decode.sh")
+	script := "#!/bin/sh\ncat >/dev/null\ndate +%s%N\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// JPEG 변환은 같은 키프레임이면 명령을 다시 실행하지 않는다
+func TestKeyframeCacheJPEG(t *testing.T) {
+	video := &mediatest.Stream{}
+	c := NewKeyframeCache(video, writeDecoder(t))
+	if !c.CanDecode() {
+		t.Fatal("CanDecode() = false with a decoder command")
+	}
+	if NewKeyframeCache(video, "").CanDecode() {
+		t.Error("CanDecode() = true without a decoder command")
+	}
+	gen := &mediatest.H264{}
+	pkts, _ := gen.Next()
+	video.Send(pkts...)
+	k, err := c.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := c.JPEG(context.Background(), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.JPEG(context.Background(), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("JPEG() ran the decoder again for the same keyframe")
+	}
+
+	failing := NewKeyframeCache(video, "false")
+	if _, err := failing.JPEG(context.Background(), k); err == nil {
+		t.Error("JPEG() with a failing decoder succeeded")
+	}
+}
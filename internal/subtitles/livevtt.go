@@ -0,0 +1,124 @@
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------- Live WebVTT ----------
+
+type liveCue struct {
+	Start, End time.Duration // epoch 기준
+	Subtitle   Data
+}
+
+// WebRTC를 쓸 수 없는 HLS/DASH 플레이어용 세그먼트 WebVTT 자막 트랙.
+// 세그먼트 n은 epoch 기준 [n*segDur, (n+1)*segDur) 구간이며 요청 시점에 생성된다.
+type LiveTrack struct {
+	mu     sync.Mutex
+	epoch  time.Time
+	segDur time.Duration
+	window int
+	cues   []liveCue
+}
+
+func NewLiveTrack(segDur time.Duration, window int) *LiveTrack {
+	if segDur < time.Second {
+		segDur = time.Second
+	}
+	if window < 1 {
+		window = 1
+	}
+	return &LiveTrack{epoch: time.Now(), segDur: segDur, window: window}
+}
+
+// 타임라인 기준점 (MPEG-TS 0). HLS 영상 세그먼트도 같은 기준을 쓴다.
+func (t *LiveTrack) Epoch() time.Time {
+	return t.epoch
+}
+
+// 최종 자막만 추가 - 직전 자막의 표시 시간은 새 자막 시작 시점에서 끝낸다
+func (t *LiveTrack) Add(subtitle Data, at time.Time) {
+	if !subtitle.IsFinal || strings.TrimSpace(subtitle.Text) == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := at.Sub(t.epoch)
+	if n := len(t.cues); n > 0 && t.cues[n-1].End > start {
+		t.cues[n-1].End = start
+	}
+	t.cues = append(t.cues, liveCue{Start: start, End: start + DefaultCueDuration, Subtitle: subtitle})
+
+	// 윈도우 밖으로 밀려난 자막 정리
+	first, _ := t.windowLocked(at)
+	windowStart := time.Duration(first) * t.segDur
+	i := 0
+	for i < len(t.cues) && t.cues[i].End <= windowStart {
+		i++
+	}
+	t.cues = t.cues[i:]
+}
+
+// 재생 목록에 노출되는 완료된 세그먼트 범위 [first, last]
+func (t *LiveTrack) windowLocked(now time.Time) (first, last int64) {
+	last = int64(now.Sub(t.epoch)/t.segDur) - 1
+	first = last - int64(t.window) + 1
+	if first < 0 {
+		first = 0
+	}
+	return first, last
+}
+
+func (t *LiveTrack) Playlist(segmentPrefix string) string {
+	t.mu.Lock()
+	first, last := t.windowLocked(time.Now())
+	epoch, segDur := t.epoch, t.segDur
+	t.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(segDur.Round(time.Second)/time.Second))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", first)
+	for n := first; n <= last; n++ {
+		segStart := epoch.Add(time.Duration(n) * segDur)
+		fmt.Fprintf(&b, "#EXT-X-PROGRAM-DATE-TIME:%s\n", segStart.UTC().Format("2006-01-02T15:04:05.000Z"))
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s%d.vtt\n", segDur.Seconds(), segmentPrefix, n)
+	}
+	return b.String()
+}
+
+// 세그먼트와 겹치는 자막을 모두 포함 (경계를 넘는 자막은 양쪽 세그먼트에 반복)
+func (t *LiveTrack) Segment(n int64) (string, bool) {
+	t.mu.Lock()
+	first, last := t.windowLocked(time.Now())
+	if n < first || n > last {
+		t.mu.Unlock()
+		return "", false
+	}
+	segStart := time.Duration(n) * t.segDur
+	segEnd := segStart + t.segDur
+	var cues []liveCue
+	for _, c := range t.cues {
+		if c.Start < segEnd && c.End > segStart {
+			cues = append(cues, c)
+		}
+	}
+	t.mu.Unlock()
+
+	// 타임라인 기준점(epoch)을 MPEG-TS 0에 대응
+	var b strings.Builder
+	b.WriteString("WEBVTT\nX-TIMESTAMP-MAP=MPEGTS:0,LOCAL:00:00:00.000\n\n")
+	for _, c := range cues {
+		text := vttCueText(c.Subtitle, TranscriptOptions{})
+		if c.Subtitle.Speaker >= 0 {
+			text = "<v " + SpeakerLabel(c.Subtitle.Speaker) + ">" + text
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", FormatCueTime(c.Start, "."), FormatCueTime(c.End, "."), text)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,73 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// epoch를 과거로 옮겨 완료된 세그먼트가 있는 트랙을 만든다
+func newTestLiveTrack(segDur time.Duration, window int, elapsed time.Duration) *LiveTrack {
+	t := NewLiveTrack(segDur, window)
+	t.epoch = time.Now().Add(-elapsed)
+	return t
+}
+
+func TestLiveTrackPlaylist(t *testing.T) {
+	track := newTestLiveTrack(2*time.Second, 3, 11*time.Second) // 세그먼트 0..4 완료
+	got := track.Playlist("live/")
+	for _, want := range []string{
+		"#EXT-X-TARGETDURATION:2\n",
+		"#EXT-X-MEDIA-SEQUENCE:2\n",
+		"live/2.vtt\n", "live/3.vtt\n", "live/4.vtt\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("playlist missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "live/1.vtt") || strings.Contains(got, "live/5.vtt") {
+		t.Errorf("playlist outside the window:\n%s", got)
+	}
+}
+
+func TestLiveTrackSegment(t *testing.T) {
+	track := newTestLiveTrack(2*time.Second, 5, 9*time.Second) // 세그먼트 0..3 완료
+	epoch := track.Epoch()
+	track.Add(Data{Text: "부분", IsFinal: false, Speaker: -1}, epoch.Add(time.Second))
+	track.Add(Data{Text: "a <b> & c", IsFinal: true, Speaker: -1}, epoch.Add(1500*time.Millisecond))
+	track.Add(Data{Text: "둘", IsFinal: true, Speaker: 1}, epoch.Add(2500*time.Millisecond))
+
+	tests := []struct {
+		n    int64
+		want []string
+		not  []string
+	}{
+		{0, []string{"00:00:01.500 --> 00:00:02.500\na &lt;b&gt; &amp; c\n"}, []string{"부분", "둘"}},
+		{1, []string{"a &lt;b&gt;", "00:00:02.500 --> 00:00:07.500\n<v Speaker 1>둘\n"}, nil}, // 경계를 넘는 자막은 반복된다
+		{3, []string{"<v Speaker 1>둘"}, []string{"a &lt;b&gt;"}},
+	}
+	for _, tt := range tests {
+		got, ok := track.Segment(tt.n)
+		if !ok {
+			t.Fatalf("Segment(%d) not available", tt.n)
+		}
+		if !strings.HasPrefix(got, "WEBVTT\nX-TIMESTAMP-MAP=MPEGTS:0,LOCAL:00:00:00.000\n\n") {
+			t.Errorf("Segment(%d) header = %q", tt.n, got)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("Segment(%d) missing %q:\n%s", tt.n, want, got)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(got, not) {
+				t.Errorf("Segment(%d) contains %q:\n%s", tt.n, not, got)
+			}
+		}
+	}
+	for _, n := range []int64{-1, 4} {
+		if _, ok := track.Segment(n); ok {
+			t.Errorf("Segment(%d) available, want outside the window", n)
+		}
+	}
+}
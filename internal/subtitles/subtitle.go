@@ -0,0 +1,242 @@
+package subtitles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------- Subtitle ----------
+
+// 자막 하나 (POST /subtitle 본문과 WebSocket subtitle 메시지의 payload)
+type Data struct {
+	Text      string `json:"text"`
+	Emotion   string `json:"emotion"`
+	Language  string `json:"language"`
+	Timestamp Time   `json:"timestamp"`
+	Speaker   int    `json:"speaker"`
+	IsFinal   bool   `json:"is_final"`
+	Emoji     string `json:"emoji"`
+	LangCode  string `json:"lang_code"`
+
+	// 서버가 부여하는 발화 추적 정보
+	UtteranceID string `json:"utterance_id,omitempty"`
+	Revision    int    `json:"revision,omitempty"`
+	Event       string `json:"event,omitempty"` // update | finalize
+
+	// 서버가 번역한 자막이면 원래 언어 코드
+	TranslatedFrom string `json:"translated_from,omitempty"`
+}
+
+// ---------- Subtitle validation ----------
+
+// README의 감정 이모지 매핑 표와 동일
+var emotionEmoji = []struct{ Emotion, Emoji string }{
+	{"HAPPY", "😊"},
+	{"SAD", "😢"},
+	{"ANGRY", "😠"},
+	{"NEUTRAL", "😐"},
+	{"FEARFUL", "😨"},
+	{"DISGUSTED", "🤢"},
+	{"SURPRISED", "😲"},
+	{"EMO_UNKNOWN", "🙂"},
+}
+
+// README의 언어 코드 표와 동일 - 첫 항목이 기본 언어
+var subtitleLanguages = []struct{ Language, LangCode string }{
+	{"ko", "KR"},
+	{"en", "EN"},
+	{"zh", "CN"},
+	{"ja", "JP"},
+	{"yue", "YUE"},
+}
+
+const maxUtteranceIDLength = 128
+
+// 초 단위 타임스탬프 - 숫자, 숫자 문자열, "hh:mm:ss(.mmm)" 형식을 모두 허용
+type Time float64
+
+func (t *Time) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = 0
+		return nil
+	}
+
+	var n float64
+	if err := json.Unmarshal(b, &n); err == nil {
+		*t = Time(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.New("timestamp must be a number of seconds")
+	}
+	v, err := parseTime(s)
+	if err != nil {
+		return err
+	}
+	*t = Time(v)
+	return nil
+}
+
+func parseTime(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("timestamp %q is not a number of seconds or hh:mm:ss", s)
+	}
+	var total float64
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("timestamp %q is not a number of seconds or hh:mm:ss", s)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
+
+// 요청 본문을 SubtitleData로 해석 - 형식 오류는 필드 단위로 설명
+func Decode(r io.Reader) (Data, error) {
+	var subtitle Data
+	if err := json.NewDecoder(r).Decode(&subtitle); err != nil {
+		return subtitle, DescribeJSONError(err)
+	}
+	return subtitle, nil
+}
+
+func DescribeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("invalid JSON at offset %d: %v", syntaxErr.Offset, err)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("field %q must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.Is(err, io.EOF):
+		return errors.New("empty request body")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("truncated JSON")
+	}
+	return err
+}
+
+// 스키마 검증 후 감정→이모지, 언어→lang_code를 서버 기준으로 정규화
+func Normalize(s *Data, maxTextLength int) error {
+	// 줄바꿈(CR/LF)과 연속 공백은 공백 하나로 - 자막은 항상 한 줄
+	s.Text = strings.Join(strings.Fields(s.Text), " ")
+	if s.Text == "" {
+		return errors.New("text is required")
+	}
+	if maxTextLength > 0 && utf8.RuneCountInString(s.Text) > maxTextLength {
+		return fmt.Errorf("text is %d characters long (max %d)", utf8.RuneCountInString(s.Text), maxTextLength)
+	}
+
+	if s.Speaker < -1 {
+		return fmt.Errorf("speaker must be -1 (unknown) or a speaker index, got %d", s.Speaker)
+	}
+	if t := float64(s.Timestamp); math.IsNaN(t) || math.IsInf(t, 0) || t < 0 {
+		return fmt.Errorf("timestamp must be a non-negative number of seconds, got %v", t)
+	}
+	if len(s.UtteranceID) > maxUtteranceIDLength {
+		return fmt.Errorf("utterance_id is longer than %d bytes", maxUtteranceIDLength)
+	}
+
+	emotion, emoji, err := canonicalEmotion(s.Emotion)
+	if err != nil {
+		return err
+	}
+	s.Emotion, s.Emoji = emotion, emoji
+
+	language, langCode, err := CanonicalLanguage(s.Language, s.LangCode)
+	if err != nil {
+		return err
+	}
+	s.Language, s.LangCode = language, langCode
+
+	// 서버가 부여하는 필드
+	s.Revision = 0
+	s.Event = ""
+	return nil
+}
+
+// SenseVoice 태그 형식(<|HAPPY|>)도 허용
+func stripSenseVoiceTag(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "<|")
+	s = strings.TrimSuffix(s, "|>")
+	return s
+}
+
+func canonicalEmotion(emotion string) (string, string, error) {
+	emotion = strings.ToUpper(stripSenseVoiceTag(emotion))
+	if emotion == "" {
+		emotion = "EMO_UNKNOWN"
+	}
+	names := make([]string, 0, len(emotionEmoji))
+	for _, e := range emotionEmoji {
+		if e.Emotion == emotion {
+			return e.Emotion, e.Emoji, nil
+		}
+		names = append(names, e.Emotion)
+	}
+	return "", "", fmt.Errorf("emotion %q is not supported (expected one of %s)", emotion, strings.Join(names, ", "))
+}
+
+func lookupLanguage(v string) (int, bool) {
+	for i, l := range subtitleLanguages {
+		if strings.EqualFold(v, l.Language) || strings.EqualFold(v, l.LangCode) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func CanonicalLanguage(language, langCode string) (string, string, error) {
+	language = stripSenseVoiceTag(language)
+	langCode = stripSenseVoiceTag(langCode)
+
+	idx := -1
+	if language != "" {
+		i, ok := lookupLanguage(language)
+		if !ok {
+			return "", "", fmt.Errorf("language %q is not supported (expected one of %s)", language, supportedLanguages())
+		}
+		idx = i
+	}
+	if langCode != "" {
+		i, ok := lookupLanguage(langCode)
+		if !ok {
+			return "", "", fmt.Errorf("lang_code %q is not supported (expected one of %s)", langCode, supportedLanguages())
+		}
+		if idx >= 0 && idx != i {
+			return "", "", fmt.Errorf("language %q does not match lang_code %q", language, langCode)
+		}
+		idx = i
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	return subtitleLanguages[idx].Language, subtitleLanguages[idx].LangCode, nil
+}
+
+func supportedLanguages() string {
+	names := make([]string, 0, len(subtitleLanguages))
+	for _, l := range subtitleLanguages {
+		names = append(names, l.Language+"/"+l.LangCode)
+	}
+	return strings.Join(names, ", ")
+}
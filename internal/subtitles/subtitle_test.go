@@ -0,0 +1,90 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      Data
+		maxLen  int
+		want    Data
+		wantErr string
+	}{
+		{
+			name: "기본값",
+			in:   Data{Text: "안녕하세요"},
+			want: Data{Text: "안녕하세요", Emotion: "EMO_UNKNOWN", Emoji: "🙂", Language: "ko", LangCode: "KR"},
+		},
+		{
+			name: "줄바꿈과 연속 공백",
+			in:   Data{Text: "  a\r\n\r\nb \t c\n"},
+			want: Data{Text: "a b c", Emotion: "EMO_UNKNOWN", Emoji: "🙂", Language: "ko", LangCode: "KR"},
+		},
+		{
+			name: "SenseVoice 태그와 대소문자",
+			in:   Data{Text: "hi", Emotion: "<|happy|>", Language: "<|en|>"},
+			want: Data{Text: "hi", Emotion: "HAPPY", Emoji: "😊", Language: "en", LangCode: "EN"},
+		},
+		{
+			name: "언어 코드만",
+			in:   Data{Text: "hi", LangCode: "jp"},
+			want: Data{Text: "hi", Emotion: "EMO_UNKNOWN", Emoji: "🙂", Language: "ja", LangCode: "JP"},
+		},
+		{
+			name: "서버 필드 초기화",
+			in:   Data{Text: "hi", Revision: 3, Event: "finalize", Speaker: -1},
+			want: Data{Text: "hi", Emotion: "EMO_UNKNOWN", Emoji: "🙂", Language: "ko", LangCode: "KR", Speaker: -1},
+		},
+		{name: "공백뿐인 본문", in: Data{Text: " \r\n "}, wantErr: "text is required"},
+		{name: "길이 초과", in: Data{Text: "가나다라"}, maxLen: 3, wantErr: "max 3"},
+		{name: "화자 번호", in: Data{Text: "hi", Speaker: -2}, wantErr: "speaker"},
+		{name: "음수 타임스탬프", in: Data{Text: "hi", Timestamp: -1}, wantErr: "timestamp"},
+		{name: "모르는 감정", in: Data{Text: "hi", Emotion: "BORED"}, wantErr: `emotion "BORED"`},
+		{name: "모르는 언어", in: Data{Text: "hi", Language: "fr"}, wantErr: `language "fr"`},
+		{name: "언어와 코드 불일치", in: Data{Text: "hi", Language: "ko", LangCode: "EN"}, wantErr: "does not match"},
+		{name: "발화 ID 길이", in: Data{Text: "hi", UtteranceID: strings.Repeat("x", 129)}, wantErr: "utterance_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			err := Normalize(&got, tt.maxLen)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Normalize() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1.5", 1.5, false},
+		{"01:02", 62, false},
+		{"01:02:03.250", 3723.25, false},
+		{"1:2:3:4", 0, true},
+		{"a:b", 0, true},
+		{"-1:00", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTime(%q) = %v, %v; want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package subtitles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------- Transcript ----------
+
+// 자막이 다음 자막 없이 끝날 때 사용하는 기본 표시 시간
+const DefaultCueDuration = 5 * time.Second
+
+type TranscriptEntry struct {
+	Offset   time.Duration // 세션 시작 기준
+	Received time.Time
+	Subtitle Data
+}
+
+// 자막 기록 세션 시작/종료
+type SessionEvent struct {
+	Event string `json:"event"` // started | ended
+	TranscriptInfo
+}
+
+type TranscriptSession struct {
+	ID        string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Entries   []TranscriptEntry
+}
+
+// 스트리밍 세션별로 최종(is_final) 자막을 모아두는 메모리 저장소
+type TranscriptStore struct {
+	mu          sync.Mutex
+	sessions    []*TranscriptSession // 오래된 순
+	current     *TranscriptSession
+	maxSessions int
+
+	// 세션 시작/종료 알림 (잠금을 쥔 채 호출되므로 저장소를 다시 호출하면 안 됨)
+	onSession func(SessionEvent)
+}
+
+// onSession은 세션 시작/종료 때마다 호출된다 (nil이면 알리지 않음)
+func NewTranscriptStore(maxSessions int, onSession func(SessionEvent)) *TranscriptStore {
+	if maxSessions < 1 {
+		maxSessions = 1
+	}
+	return &TranscriptStore{maxSessions: maxSessions, onSession: onSession}
+}
+
+// 새 세션 시작 - 진행 중인 세션은 종료 처리
+func (s *TranscriptStore) StartSession() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startLocked(time.Now()).ID
+}
+
+func (s *TranscriptStore) EndSession() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		s.current.EndedAt = time.Now()
+		s.notifyLocked("ended", s.current)
+		s.current = nil
+	}
+}
+
+func (s *TranscriptStore) startLocked(now time.Time) *TranscriptSession {
+	if s.current != nil {
+		s.current.EndedAt = now
+		s.notifyLocked("ended", s.current)
+	}
+
+	id := now.Format("20060102-150405")
+	for n := 2; s.findLocked(id) != nil; n++ {
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+
+	s.current = &TranscriptSession{ID: id, StartedAt: now}
+	s.sessions = append(s.sessions, s.current)
+	if len(s.sessions) > s.maxSessions {
+		s.sessions = s.sessions[len(s.sessions)-s.maxSessions:]
+	}
+	log.Printf("Transcript session started: %s", id)
+	s.notifyLocked("started", s.current)
+	return s.current
+}
+
+func (s *TranscriptStore) notifyLocked(event string, sess *TranscriptSession) {
+	if s.onSession != nil {
+		s.onSession(SessionEvent{Event: event, TranscriptInfo: sess.info()})
+	}
+}
+
+func (s *TranscriptStore) findLocked(id string) *TranscriptSession {
+	for _, sess := range s.sessions {
+		if sess.ID == id {
+			return sess
+		}
+	}
+	return nil
+}
+
+// 최종 자막만 기록 - 스트리밍 세션이 없으면 암묵적으로 세션을 시작
+func (s *TranscriptStore) Add(subtitle Data, at time.Time) {
+	if !subtitle.IsFinal || strings.TrimSpace(subtitle.Text) == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		s.startLocked(at)
+	}
+	s.current.Entries = append(s.current.Entries, TranscriptEntry{
+		Offset:   at.Sub(s.current.StartedAt),
+		Received: at,
+		Subtitle: subtitle,
+	})
+}
+
+// id가 비어있거나 "latest"이면 가장 최근 세션을 반환
+func (s *TranscriptStore) Get(id string) (TranscriptSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sess *TranscriptSession
+	if id == "" || id == "latest" {
+		if len(s.sessions) > 0 {
+			sess = s.sessions[len(s.sessions)-1]
+		}
+	} else {
+		sess = s.findLocked(id)
+	}
+	if sess == nil {
+		return TranscriptSession{}, false
+	}
+
+	snapshot := *sess
+	snapshot.Entries = append([]TranscriptEntry(nil), sess.Entries...)
+	return snapshot, true
+}
+
+// 주어진 구간에 최종 자막이 기록된 세션 ID (오래된 순)
+func (s *TranscriptStore) SessionsBetween(start, end time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for _, sess := range s.sessions {
+		for _, e := range sess.Entries {
+			if !e.Received.Before(start) && !e.Received.After(end) {
+				ids = append(ids, sess.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// 주어진 구간에 받은 최종 자막 (받은 순)
+func (s *TranscriptStore) EntriesBetween(start, end time.Time) []TranscriptEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []TranscriptEntry
+	for _, sess := range s.sessions {
+		for _, e := range sess.Entries {
+			if !e.Received.Before(start) && e.Received.Before(end) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries
+}
+
+// 발화 ID로 최종 자막을 찾는다 (같은 ID가 여러 번이면 가장 최근 것)
+func (s *TranscriptStore) FindUtterance(id string) (TranscriptEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.sessions) - 1; i >= 0; i-- {
+		entries := s.sessions[i].Entries
+		for j := len(entries) - 1; j >= 0; j-- {
+			if entries[j].Subtitle.UtteranceID == id {
+				return entries[j], true
+			}
+		}
+	}
+	return TranscriptEntry{}, false
+}
+
+type TranscriptInfo struct {
+	ID        string     `json:"id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Entries   int        `json:"entries"`
+}
+
+func (s *TranscriptStore) List() []TranscriptInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]TranscriptInfo, 0, len(s.sessions))
+	for i := len(s.sessions) - 1; i >= 0; i-- {
+		list = append(list, s.sessions[i].info())
+	}
+	return list
+}
+
+func (sess *TranscriptSession) info() TranscriptInfo {
+	info := TranscriptInfo{ID: sess.ID, StartedAt: sess.StartedAt, Entries: len(sess.Entries)}
+	if !sess.EndedAt.IsZero() {
+		ended := sess.EndedAt
+		info.EndedAt = &ended
+	}
+	return info
+}
+
+// ---------- Export ----------
+
+type Cue struct {
+	Start, End time.Duration
+	Subtitle   Data
+}
+
+type TranscriptOptions struct {
+	Speakers bool
+	Emotions bool
+}
+
+// 자막 종료 시간은 다음 자막 시작 또는 기본 표시 시간 중 빠른 쪽
+func (sess *TranscriptSession) Cues() []Cue {
+	cues := make([]Cue, len(sess.Entries))
+	for i, e := range sess.Entries {
+		end := e.Offset + DefaultCueDuration
+		if i+1 < len(sess.Entries) && sess.Entries[i+1].Offset < end {
+			end = sess.Entries[i+1].Offset
+		}
+		if !sess.EndedAt.IsZero() {
+			if limit := sess.EndedAt.Sub(sess.StartedAt); end > limit {
+				end = limit
+			}
+		}
+		if end <= e.Offset {
+			end = e.Offset + time.Millisecond
+		}
+		cues[i] = Cue{Start: e.Offset, End: end, Subtitle: e.Subtitle}
+	}
+	return cues
+}
+
+// 한 줄짜리 큐 본문. 줄바꿈은 공백으로 바꿔 빈 줄이 큐를 일찍 끝내지 않게 한다.
+func CueText(sub Data, opts TranscriptOptions) string {
+	text := strings.Join(strings.Fields(sub.Text), " ")
+	if opts.Emotions && sub.Emotion != "" {
+		text = "[" + sub.Emotion + "] " + text
+	}
+	return text
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WebVTT 큐 본문 - 태그/엔터티로 읽히지 않게 이스케이프 ("-->"도 "--&gt;"가 된다)
+func vttCueText(sub Data, opts TranscriptOptions) string {
+	return vttEscaper.Replace(CueText(sub, opts))
+}
+
+// SRT에는 이스케이프가 없으므로 타이밍 줄로 읽힐 "-->"만 바꾼다
+func srtCueText(sub Data, opts TranscriptOptions) string {
+	return strings.ReplaceAll(CueText(sub, opts), "-->", "->")
+}
+
+func SpeakerLabel(speaker int) string {
+	if speaker < 0 {
+		return "Unknown"
+	}
+	return fmt.Sprintf("Speaker %d", speaker)
+}
+
+// hh:mm:ss.mmm (sep로 밀리초 구분자 지정)
+func FormatCueTime(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+func WriteWebVTT(w io.Writer, cues []Cue, opts TranscriptOptions) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+	for i, c := range cues {
+		text := vttCueText(c.Subtitle, opts)
+		if opts.Speakers {
+			text = "<v " + SpeakerLabel(c.Subtitle.Speaker) + ">" + text
+		}
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			FormatCueTime(c.Start, "."), FormatCueTime(c.End, "."), text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WriteSRT(w io.Writer, cues []Cue, opts TranscriptOptions) error {
+	for i, c := range cues {
+		text := srtCueText(c.Subtitle, opts)
+		if opts.Speakers {
+			text = SpeakerLabel(c.Subtitle.Speaker) + ": " + text
+		}
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			FormatCueTime(c.Start, ","), FormatCueTime(c.End, ","), text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WritePlainTranscript(w io.Writer, cues []Cue, opts TranscriptOptions) error {
+	for _, c := range cues {
+		line := "[" + FormatCueTime(c.Start, ".")[:8] + "] "
+		if opts.Speakers {
+			line += SpeakerLabel(c.Subtitle.Speaker) + ": "
+		}
+		if _, err := io.WriteString(w, line+CueText(c.Subtitle, opts)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type transcriptJSONCue struct {
+	UtteranceID string  `json:"utterance_id,omitempty"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Text        string  `json:"text"`
+	Speaker     *int    `json:"speaker,omitempty"`
+	Emotion     string  `json:"emotion,omitempty"`
+	Emoji       string  `json:"emoji,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	LangCode    string  `json:"lang_code,omitempty"`
+}
+
+func WriteJSONTranscript(w io.Writer, sess TranscriptSession, cues []Cue, opts TranscriptOptions) error {
+	out := struct {
+		TranscriptInfo
+		Cues []transcriptJSONCue `json:"cues"`
+	}{TranscriptInfo: sess.info(), Cues: make([]transcriptJSONCue, 0, len(cues))}
+
+	for _, c := range cues {
+		jc := transcriptJSONCue{
+			UtteranceID: c.Subtitle.UtteranceID,
+			Start:       c.Start.Seconds(),
+			End:         c.End.Seconds(),
+			Text:        strings.TrimSpace(c.Subtitle.Text),
+			Language:    c.Subtitle.Language,
+			LangCode:    c.Subtitle.LangCode,
+		}
+		if opts.Speakers {
+			speaker := c.Subtitle.Speaker
+			jc.Speaker = &speaker
+		}
+		if opts.Emotions {
+			jc.Emotion = c.Subtitle.Emotion
+			jc.Emoji = c.Subtitle.Emoji
+		}
+		out.Cues = append(out.Cues, jc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
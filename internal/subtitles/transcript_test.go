@@ -0,0 +1,84 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTranscript(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 1500 * time.Millisecond, Subtitle: Data{Text: "안녕", Emotion: "HAPPY", Speaker: 0}},
+		{Start: 3723 * time.Second, End: 3725 * time.Second, Subtitle: Data{Text: "a\n\nb <i>x</i> & y --> z", Speaker: -1}},
+	}
+	tests := []struct {
+		name  string
+		write func(*strings.Builder, []Cue, TranscriptOptions) error
+		opts  TranscriptOptions
+		want  string
+	}{
+		{
+			name:  "WebVTT",
+			write: func(b *strings.Builder, c []Cue, o TranscriptOptions) error { return WriteWebVTT(b, c, o) },
+			want: "WEBVTT\n\n" +
+				"1\n00:00:00.000 --> 00:00:01.500\n안녕\n\n" +
+				"2\n01:02:03.000 --> 01:02:05.000\na b &lt;i&gt;x&lt;/i&gt; &amp; y --&gt; z\n\n",
+		},
+		{
+			name:  "WebVTT 화자와 감정",
+			write: func(b *strings.Builder, c []Cue, o TranscriptOptions) error { return WriteWebVTT(b, c, o) },
+			opts:  TranscriptOptions{Speakers: true, Emotions: true},
+			want: "WEBVTT\n\n" +
+				"1\n00:00:00.000 --> 00:00:01.500\n<v Speaker 0>[HAPPY] 안녕\n\n" +
+				"2\n01:02:03.000 --> 01:02:05.000\n<v Unknown>a b &lt;i&gt;x&lt;/i&gt; &amp; y --&gt; z\n\n",
+		},
+		{
+			name:  "SRT",
+			write: func(b *strings.Builder, c []Cue, o TranscriptOptions) error { return WriteSRT(b, c, o) },
+			opts:  TranscriptOptions{Speakers: true},
+			want: "1\n00:00:00,000 --> 00:00:01,500\nSpeaker 0: 안녕\n\n" +
+				"2\n01:02:03,000 --> 01:02:05,000\nUnknown: a b <i>x</i> & y -> z\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.write(&b, cues, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+// 큐는 다음 자막 시작, 기본 표시 시간, 세션 종료 중 가장 이른 때에 끝난다
+func TestCues(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sess := TranscriptSession{
+		StartedAt: start,
+		EndedAt:   start.Add(12 * time.Second),
+		Entries: []TranscriptEntry{
+			{Offset: 0},
+			{Offset: 2 * time.Second},
+			{Offset: 10 * time.Second},
+			{Offset: 12 * time.Second},
+		},
+	}
+	want := []struct{ start, end time.Duration }{
+		{0, 2 * time.Second},
+		{2 * time.Second, 7 * time.Second},
+		{10 * time.Second, 12 * time.Second},
+		{12 * time.Second, 12*time.Second + time.Millisecond},
+	}
+	cues := sess.Cues()
+	if len(cues) != len(want) {
+		t.Fatalf("got %d cues, want %d", len(cues), len(want))
+	}
+	for i, w := range want {
+		if cues[i].Start != w.start || cues[i].End != w.end {
+			t.Errorf("cue %d = %s-%s, want %s-%s", i, cues[i].Start, cues[i].End, w.start, w.end)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package subtitles
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ---------- Subtitle translation ----------
+
+// 음성인식이 내지 않은 언어의 자막을 만든다. source/target은 ISO 언어 코드 (ko, en, ...)
+type Translator interface {
+	Translate(ctx context.Context, text, source, target string) (string, error)
+}
+
+// LibreTranslate 호환 HTTP 번역기 (POST {q, source, target} → {translatedText}).
+// 로컬에 띄운 번역 서버를 가리키는 용도이며, 다른 서비스는 같은 형식의 프록시를 앞에 둔다.
+type HTTPTranslator struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTPTranslator(url, apiKey string, timeout time.Duration) *HTTPTranslator {
+	return &HTTPTranslator{url: url, apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+func (t *HTTPTranslator) Translate(ctx context.Context, text, source, target string) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"q":       text,
+		"source":  source,
+		"target":  target,
+		"format":  "text",
+		"api_key": t.apiKey,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return "", fmt.Errorf("translator: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var out struct {
+		TranslatedText string `json:"translatedText"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&out); err != nil {
+		return "", fmt.Errorf("translator: %w", err)
+	}
+	return out.TranslatedText, nil
+}
+
+const translationQueue = 64
+
+// 번역 결과를 받는 쪽 (보통 hub). Languages는 시청자가 요청한 언어 코드 목록이다.
+type TranslationSink interface {
+	Languages() []string
+	PublishSubtitle(subtitle Data) error
+}
+
+// 최종 자막을 시청자가 요청한 언어로 번역해 sink로 보낸다.
+// 순서를 지키기 위해 작업자 하나가 차례로 처리하고, 밀리면 새 자막을 버린다.
+type Translations struct {
+	translator    Translator
+	sink          TranslationSink
+	timeout       time.Duration
+	maxTextLength int
+	queue         chan Data
+}
+
+func NewTranslations(t Translator, sink TranslationSink, timeout time.Duration, maxTextLength int) *Translations {
+	s := &Translations{
+		translator:    t,
+		sink:          sink,
+		timeout:       timeout,
+		maxTextLength: maxTextLength,
+		queue:         make(chan Data, translationQueue),
+	}
+	go s.run()
+	return s
+}
+
+// 부분 자막은 번역하지 않는다
+func (s *Translations) Submit(subtitle Data) {
+	if s == nil || !subtitle.IsFinal || subtitle.TranslatedFrom != "" {
+		return
+	}
+	select {
+	case s.queue <- subtitle:
+	default:
+		log.Printf("Translation queue full, dropping %s", subtitle.UtteranceID)
+	}
+}
+
+func (s *Translations) run() {
+	for subtitle := range s.queue {
+		for _, target := range s.sink.Languages() {
+			if target != subtitle.LangCode {
+				s.translate(subtitle, target)
+			}
+		}
+	}
+}
+
+func (s *Translations) translate(subtitle Data, langCode string) {
+	language, langCode, err := CanonicalLanguage("", langCode)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	text, err := s.translator.Translate(ctx, subtitle.Text, subtitle.Language, language)
+	if err != nil {
+		log.Printf("Translation %s -> %s failed: %v", subtitle.LangCode, langCode, err)
+		return
+	}
+	if text == "" {
+		return
+	}
+	if r := []rune(text); s.maxTextLength > 0 && len(r) > s.maxTextLength {
+		text = string(r[:s.maxTextLength])
+	}
+
+	translated := subtitle
+	translated.Text = text
+	translated.Language, translated.LangCode = language, langCode
+	translated.TranslatedFrom = subtitle.LangCode
+	s.sink.PublishSubtitle(translated)
+}
@@ -0,0 +1,82 @@
+package subtitles
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ---------- Utterance tracking ----------
+
+const (
+	EventUpdate   = "update"
+	EventFinalize = "finalize"
+)
+
+// 이 시간 동안 갱신이 없는 부분 자막은 버려진 발화로 간주
+const utteranceIdleTimeout = 10 * time.Second
+
+type openUtterance struct {
+	revision int
+	updated  time.Time
+}
+
+// 부분(is_final=false) 자막과 최종 자막을 같은 발화 ID로 묶는다.
+// 생산자가 utterance_id를 보내면 그대로 사용하고, 없으면 서버가 부여한다.
+type UtteranceTracker struct {
+	mu      sync.Mutex
+	prefix  string
+	seq     uint64
+	current string // 서버가 부여한 진행 중인 발화
+	open    map[string]*openUtterance
+}
+
+func NewUtteranceTracker() *UtteranceTracker {
+	return &UtteranceTracker{
+		prefix: strconv.FormatInt(time.Now().Unix(), 36),
+		open:   make(map[string]*openUtterance),
+	}
+}
+
+// UtteranceID, Revision, Event 필드를 채운다
+func (t *UtteranceTracker) Assign(s *Data, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, u := range t.open {
+		if now.Sub(u.updated) > utteranceIdleTimeout {
+			delete(t.open, id)
+			if id == t.current {
+				t.current = ""
+			}
+		}
+	}
+
+	if s.UtteranceID == "" {
+		if t.current == "" {
+			t.seq++
+			t.current = fmt.Sprintf("%s-%d", t.prefix, t.seq)
+		}
+		s.UtteranceID = t.current
+	}
+
+	u, ok := t.open[s.UtteranceID]
+	if !ok {
+		u = &openUtterance{}
+		t.open[s.UtteranceID] = u
+	}
+	u.revision++
+	u.updated = now
+	s.Revision = u.revision
+
+	if s.IsFinal {
+		s.Event = EventFinalize
+		delete(t.open, s.UtteranceID)
+		if s.UtteranceID == t.current {
+			t.current = ""
+		}
+	} else {
+		s.Event = EventUpdate
+	}
+}
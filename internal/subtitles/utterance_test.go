@@ -0,0 +1,46 @@
+package subtitles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUtteranceTracker(t *testing.T) {
+	tr := NewUtteranceTracker()
+	now := time.Now()
+	assign := func(s Data, at time.Time) Data {
+		tr.Assign(&s, at)
+		return s
+	}
+
+	p1 := assign(Data{Text: "안"}, now)
+	p2 := assign(Data{Text: "안녕"}, now.Add(time.Second))
+	f := assign(Data{Text: "안녕하세요", IsFinal: true}, now.Add(2*time.Second))
+	if p1.UtteranceID == "" || p2.UtteranceID != p1.UtteranceID || f.UtteranceID != p1.UtteranceID {
+		t.Fatalf("ids = %q %q %q, want one utterance", p1.UtteranceID, p2.UtteranceID, f.UtteranceID)
+	}
+	if p1.Revision != 1 || p2.Revision != 2 || f.Revision != 3 {
+		t.Errorf("revisions = %d %d %d, want 1 2 3", p1.Revision, p2.Revision, f.Revision)
+	}
+	if p1.Event != EventUpdate || f.Event != EventFinalize {
+		t.Errorf("events = %q %q", p1.Event, f.Event)
+	}
+
+	// 최종 자막 다음은 새 발화
+	next := assign(Data{Text: "다음"}, now.Add(3*time.Second))
+	if next.UtteranceID == f.UtteranceID || next.Revision != 1 {
+		t.Errorf("next = %q rev %d, want a new utterance", next.UtteranceID, next.Revision)
+	}
+	// 갱신 없이 오래 지나면 버려진 발화로 보고 새로 시작
+	stale := assign(Data{Text: "늦음"}, now.Add(3*time.Second+utteranceIdleTimeout+time.Second))
+	if stale.UtteranceID == next.UtteranceID || stale.Revision != 1 {
+		t.Errorf("after idle = %q rev %d, want a new utterance", stale.UtteranceID, stale.Revision)
+	}
+
+	// 생산자가 보낸 ID는 그대로 쓴다
+	a := assign(Data{Text: "x", UtteranceID: "ext"}, now)
+	b := assign(Data{Text: "xy", UtteranceID: "ext", IsFinal: true}, now)
+	if a.UtteranceID != "ext" || b.Revision != 2 || b.Event != EventFinalize {
+		t.Errorf("producer id = %q rev %d %q", a.UtteranceID, b.Revision, b.Event)
+	}
+}
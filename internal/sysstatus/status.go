@@ -1,16 +1,26 @@
-package main
+package sysstatus
 
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
-func getSystemStatus() SystemStatus {
-	status := SystemStatus{
+// 장치 상태 (배터리, 네트워크, 온도, 저장 공간)
+type Status struct {
+	Battery     string `json:"battery"`
+	Signal      string `json:"signal"`
+	Temperature string `json:"temperature"`
+	Storage     string `json:"storage"`
+}
+
+func Get() Status {
+	status := Status{
 		Battery:     getBatteryStatus(),
 		Signal:      getNetworkStatus(),
 		Temperature: getCPUTemperature(),
@@ -257,21 +267,36 @@ func getCPUTemperature() string {
 }
 
 func getStorageStatus() string {
-	cmd := exec.Command("df", "-h", "/")
-	output, err := cmd.Output()
+	free, total, err := DiskSpace("/")
 	if err != nil {
 		return "N/A"
 	}
+	return fmt.Sprintf("%s Free / %s", FormatDiskSize(free), FormatDiskSize(total))
+}
 
-	lines := strings.Split(string(output), "\n")
-	if len(lines) >= 2 {
-		fields := strings.Fields(lines[1])
-		if len(fields) >= 4 {
-			total := fields[1] // 전체 용량
-			avail := fields[3] // 사용 가능 용량
-			return fmt.Sprintf("%s Free / %s", avail, total)
-		}
+// 경로가 속한 파일시스템의 사용 가능/전체 용량 (바이트, df와 같은 기준)
+func DiskSpace(path string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
 	}
+	return st.Bavail * uint64(st.Bsize), st.Blocks * uint64(st.Bsize), nil
+}
 
-	return "N/A"
+// df -h 형식 (예: 9.5G, 52G)
+func FormatDiskSize(b uint64) string {
+	const units = "BKMGTPE"
+	v := float64(b)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d%c", b, units[0])
+	}
+	if v < 10 {
+		return fmt.Sprintf("%.1f%c", math.Ceil(v*10)/10, units[i])
+	}
+	return fmt.Sprintf("%.0f%c", math.Ceil(v), units[i])
 }
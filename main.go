@@ -1,63 +1,82 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"strconv"
-)
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
 
-var hub *Hub
+	"webrtc-streamer/internal/config"
+	"webrtc-streamer/streamer"
+)
 
 func main() {
-	// WebSocket Hub 초기화
-	hub = newHub()
-	go hub.run()
-
-	// 정적 파일 서버에 캐시 방지 미들웨어 추가
-	fs := http.FileServer(http.Dir("./static"))
-	http.Handle("/", noCacheMiddleware(fs))
-
-	// WebSocket 엔드포인트
-	http.HandleFunc("/ws", handleWebSocket)
+	flag.Parse()
 
-	// HTTP 엔드포인트
-	http.HandleFunc("/post", handlePost)
-	http.HandleFunc("/reset", handleReset)
-	http.HandleFunc("/subtitle", handleSubtitle)
-	http.HandleFunc("/status", handleStatus)
+	cfg, err := loadConfig()
+	if *printConfig {
+		// 검증에 실패해도 읽은 값은 보여준다
+		if cfg != nil {
+			cfg.Write(os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid configuration:\n%s\n", formatErrors(err))
+			os.Exit(2)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%s", formatErrors(err))
+	}
 
-	port := getenvInt("HTTP_PORT", 8080)
-	addr := ":" + strconv.Itoa(port)
+	// 인증 설정은 SIGHUP으로 바뀔 수 있으므로 요청마다 현재 값을 본다
+	var auth atomic.Pointer[config.Auth]
+	auth.Store(&cfg.Auth)
+	opts := serverOptions(cfg)
+	opts.Authorize = func(r *http.Request) error { return auth.Load().Authorize(r) }
+	opts.AuthorizeAdmin = func(r *http.Request) error { return auth.Load().Require(r) }
 
 	log.Printf("🚀 OMNISENSE Server starting...")
-	log.Printf("📍 Server: http://localhost:%d", port)
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatal("HTTP Server error: ", err)
+	srv, err := streamer.New(opts)
+	if err != nil {
+		log.Fatal("Server error: ", err)
+	}
+	if err := srv.Start(); err != nil {
+		log.Fatal("Server error: ", err)
 	}
-}
 
-// 캐시 방지 미들웨어 - 개발 중에는 캐시 비활성화
-func noCacheMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// HTML, CSS, JS 파일은 캐시 방지
-		if isDevResource(r.URL.Path) {
-			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-			w.Header().Set("Pragma", "no-cache")
-			w.Header().Set("Expires", "0")
-		} else {
-			// 이미지 등 정적 리소스는 짧은 캐시 허용
-			w.Header().Set("Cache-Control", "public, max-age=300") // 5분
+	// SIGINT/SIGTERM (docker stop/restart) - 두 번째 신호는 바로 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case <-hup:
+			reloadConfig(cfg, srv, &auth)
+		case <-ctx.Done():
+			break wait
 		}
-		next.ServeHTTP(w, r)
-	})
+	}
+	stop()
+
+	log.Printf("Shutdown deadline %s", cfg.HTTP.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Stop(shutdownCtx); err != nil {
+		log.Printf("Shutdown deadline exceeded, exiting")
+		os.Exit(1)
+	}
 }
 
-// 개발 리소스 확인 (HTML, CSS, JS)
-func isDevResource(path string) bool {
-	return path == "/" || 
-		path == "/index.html" ||
-		len(path) >= 4 && (path[len(path)-4:] == ".css" || 
-		                   path[len(path)-3:] == ".js" ||
-		                   path[len(path)-5:] == ".html")
+// errors.Join으로 묶인 오류를 한 줄에 하나씩
+func formatErrors(err error) string {
+	return "  - " + strings.ReplaceAll(err.Error(), "\n", "\n  - ")
 }
@@ -8,14 +8,24 @@ echo "Starting video and audio streaming..."
 # Use localhost for reliable local streaming
 HOST="127.0.0.1"
 export HOST  # Export so it's passed to child scripts
-VIDEO_PORT=5004
-AUDIO_PORT=5006
 
-echo "Using server address: $HOST (localhost)"
+# Read RTP ports from the server's effective configuration
+# (CONFIG_FILE, RTP_PORT, RTP_AUDIO_PORT) so both sides agree.
+# go run works on a clean checkout without a prebuilt binary.
+SERVER_CONFIG="$(go run . --print-config 2>/dev/null || true)"
+config_value() {
+  echo "$SERVER_CONFIG" | awk -F' = ' -v key="$1" '$1 == key {print $2; exit}'
+}
+VIDEO_PORT="$(config_value video_port)"
+AUDIO_PORT="$(config_value audio_port)"
+VIDEO_PORT="${VIDEO_PORT:-5004}"
+AUDIO_PORT="${AUDIO_PORT:-5006}"
+
+echo "Using server address: $HOST (localhost), video port $VIDEO_PORT, audio port $AUDIO_PORT"
 
 # Start video stream in background
 echo "Starting video stream..."
-bash ./vstream.sh &
+PORT="$VIDEO_PORT" bash ./vstream.sh &
 VIDEO_PID=$!
 
 # Wait a moment before starting audio
@@ -23,7 +33,7 @@ sleep 2
 
 # Start audio stream in background
 echo "Starting audio stream..."
-bash ./astream.sh &
+PORT="$AUDIO_PORT" bash ./astream.sh &
 AUDIO_PID=$!
 
 echo "Both streams started. Video PID: $VIDEO_PID, Audio PID: $AUDIO_PID"
@@ -86,6 +86,7 @@ window.translations = {
     msg_streaming_disconnected: '스트리밍 연결 끊김',
     msg_streaming_checking: '스트리밍 연결 중...',
     msg_streaming_complete: '스트리밍 연결 완료',
+    msg_streaming_hls: 'HLS로 재생 중 (지연 시간이 깁니다)',
     msg_error_occurred: '오류가 발생했습니다',
     msg_already_streaming: '이미 스트리밍이 진행 중입니다. 잠시 후 다시 시도해주세요.',
     msg_network_error: '서버에 연결할 수 없습니다. 네트워크 연결을 확인해주세요.',
@@ -191,6 +192,7 @@ window.translations = {
     msg_streaming_disconnected: 'Streaming disconnected',
     msg_streaming_checking: 'Checking streaming connection...',
     msg_streaming_complete: 'Streaming connection complete',
+    msg_streaming_hls: 'Playing via HLS (higher latency)',
     msg_error_occurred: 'An error occurred',
     msg_already_streaming: 'Stream already in progress. Please try again later.',
     msg_network_error: 'Cannot connect to server. Please check your network connection.',
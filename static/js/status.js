@@ -3,6 +3,9 @@
 // 중요한 상태 변경 추적
 let lastImportantStatus = {};
 
+// WebSocket으로 상태를 받고 있으면 HTTP 조회를 건너뜀
+let lastStatusPush = 0;
+
 // 시스템 상태 업데이트 시작 - window 객체에 할당
 window.startSystemStatusUpdates = function() {
   // 즉시 한 번 업데이트
@@ -14,6 +17,9 @@ window.startSystemStatusUpdates = function() {
 
 // 시스템 상태 업데이트 - window 객체에 할당
 window.updateSystemStatus = async function() {
+  if (Date.now() - lastStatusPush < 15000) {
+    return;
+  }
   try {
     const controller = new AbortController();
     const timeoutId = setTimeout(() => controller.abort(), 5000);
@@ -30,13 +36,7 @@ window.updateSystemStatus = async function() {
     }
     
     const status = await response.json();
-    
-    updateStatusDisplay('battery', status.battery);
-    updateStatusDisplay('signal', status.signal);
-    updateStatusDisplay('temperature', status.temperature);
-    updateStatusDisplay('storage', status.storage);
-    
-    announceImportantStatusChanges(status);
+    window.applySystemStatus(status, false);
     
   } catch (error) {
     if (error.name === 'AbortError') {
@@ -52,6 +52,19 @@ window.updateSystemStatus = async function() {
   }
 }
 
+// 상태 표시 갱신 (pushed: WebSocket으로 받은 경우) - window 객체에 할당
+window.applySystemStatus = function(status, pushed) {
+  if (pushed) {
+    lastStatusPush = Date.now();
+  }
+  updateStatusDisplay('battery', status.battery);
+  updateStatusDisplay('signal', status.signal);
+  updateStatusDisplay('temperature', status.temperature);
+  updateStatusDisplay('storage', status.storage);
+  
+  announceImportantStatusChanges(status);
+}
+
 // 개별 상태 표시 업데이트 함수
 function updateStatusDisplay(type, value) {
   const statusCards = document.querySelectorAll('.status-card');
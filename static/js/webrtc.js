@@ -79,11 +79,13 @@ window.initializePeerConnection = function() {
       log('WebRTC connection failed');
       updateStreamStatus(window.t('msg_streaming_failed'));
       
-      // 실패 원인 분석
-      analyzeConnectionFailure().then(reason => {
-        const errorMsg = `연결 실패: ${reason}`;
-        showErrorMessage(errorMsg);
-      });
+      // UDP가 막힌 환경이면 HLS로 전환, 불가능하면 실패 원인 표시
+      if (!startHLSFallback()) {
+        analyzeConnectionFailure().then(reason => {
+          const errorMsg = `연결 실패: ${reason}`;
+          showErrorMessage(errorMsg);
+        });
+      }
     } else if (newPc.iceConnectionState === 'checking') {
       log('Checking ICE connection...');
       updateStreamStatus(window.t('msg_streaming_checking'));
@@ -123,6 +125,31 @@ window.initializePeerConnection = function() {
   return newPc;
 }
 
+// 브라우저가 HLS를 직접 재생할 수 있으면 서버의 HLS 출력으로 전환
+function startHLSFallback() {
+  const el = document.createElement('video');
+  if (!el.canPlayType('application/vnd.apple.mpegurl')) {
+    log('HLS fallback not supported by this browser');
+    return false;
+  }
+
+  el.src = '/live/index.m3u8';
+  el.autoplay = true;
+  el.playsInline = true;
+  el.controls = false;
+  el.style.width = '100%';
+  el.style.height = '100%';
+  el.style.objectFit = 'contain';
+
+  const videoPlayer = document.getElementById('remoteVideo');
+  videoPlayer.innerHTML = '';
+  videoPlayer.appendChild(el);
+
+  log('Switched to HLS fallback');
+  updateStreamStatus(window.t('msg_streaming_hls'));
+  return true;
+}
+
 // WebRTC 연결 정리를 위한 함수 - window 객체에 할당
 window.cleanupWebRTC = function() {
   if (!window.pc) return;
@@ -5,8 +5,17 @@ let subtitleTimeout;
 let wsReconnectAttempts = 0;
 const maxReconnectAttempts = 5;
 
+// WebSocket이 한 번도 열리지 않고 이만큼 실패하면 SSE(/events)로 전환
+const wsFallbackAttempts = 2;
+let wsEverOpened = false;
+let eventSource;
+
 // WebSocket 연결 및 자막 처리 - window 객체에 할당
 window.connectWebSocket = function() {
+  if (!window.WebSocket) {
+    connectEventSource();
+    return;
+  }
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
   const wsUrl = `${protocol}//${window.location.host}/ws`;
   
@@ -21,23 +30,35 @@ window.connectWebSocket = function() {
       window.announceToScreenReader(window.t('msg_subtitle_connected'));
     }
     wsReconnectAttempts = 0; // 연결 성공 시 재연결 시도 횟수 리셋
+    wsEverOpened = true;
+    if (subtitleLanguages.length > 0) {
+      sendSubtitleLanguages();
+    }
   };
   
   ws.onmessage = function(event) {
-    try {
-      const subtitleData = JSON.parse(event.data);
-      console.log('Received subtitle data:', subtitleData);
-      console.log('Current isStreaming state:', window.isStreaming);
-      updateSubtitleOverlay(subtitleData);
-    } catch (error) {
-      console.error('Failed to parse subtitle data:', error);
-    }
+    // 한 프레임에 여러 메시지가 줄바꿈으로 이어져 올 수 있음
+    event.data.split('\n').forEach(line => {
+      if (!line.trim()) return;
+      try {
+        handleServerMessage(JSON.parse(line));
+      } catch (error) {
+        console.error('Failed to parse WebSocket message:', error);
+      }
+    });
   };
   
   ws.onclose = function(event) {
     if (!event.wasClean) {
       console.log('WebSocket connection closed unexpectedly');
     }
+
+    // 프록시 등이 WebSocket을 막는 환경
+    if (!wsEverOpened && wsReconnectAttempts >= wsFallbackAttempts - 1 && window.EventSource) {
+      console.log('WebSocket unavailable, falling back to Server-Sent Events');
+      connectEventSource();
+      return;
+    }
     
     if (wsReconnectAttempts < maxReconnectAttempts) {
       wsReconnectAttempts++;
@@ -53,6 +74,106 @@ window.connectWebSocket = function() {
   };
 }
 
+// SSE는 같은 봉투를 이벤트 이름(type)별로 보내며, 끊기면 브라우저가 Last-Event-ID로 이어받는다
+function connectEventSource() {
+  if (eventSource) {
+    eventSource.close();
+  }
+  const params = new URLSearchParams();
+  if (subtitleLanguages.length > 0) {
+    params.set('languages', subtitleLanguages.join(','));
+  }
+  if (lastMessageSeq > 0) {
+    params.set('last_event_id', lastMessageSeq);
+  }
+  eventSource = new EventSource(`/events?${params}`);
+
+  eventSource.onopen = function() {
+    console.log('Event stream connected for subtitles');
+    if (typeof window.log === 'function') {
+      window.log('Event stream connected for subtitles');
+    }
+  };
+  Object.keys(messageHandlers).forEach(type => {
+    eventSource.addEventListener(type, event => {
+      try {
+        handleServerMessage(JSON.parse(event.data));
+      } catch (error) {
+        console.error('Failed to parse event:', error);
+      }
+    });
+  });
+  eventSource.onerror = function() {
+    console.log('Event stream interrupted, browser will reconnect');
+  };
+}
+
+// 받을 자막 언어 (빈 배열이면 모든 언어의 원본) - 재연결 시 다시 보냄
+let subtitleLanguages = JSON.parse(localStorage.getItem('subtitle_languages') || '[]');
+
+function sendSubtitleLanguages() {
+  if (eventSource) {
+    // SSE는 요청을 보낼 수 없으므로 새 언어로 다시 접속
+    connectEventSource();
+    return;
+  }
+  if (ws && ws.readyState === WebSocket.OPEN) {
+    ws.send(JSON.stringify({ type: 'languages', languages: subtitleLanguages }));
+  }
+}
+
+window.setSubtitleLanguages = function(languages) {
+  subtitleLanguages = languages || [];
+  localStorage.setItem('subtitle_languages', JSON.stringify(subtitleLanguages));
+  sendSubtitleLanguages();
+};
+
+// 서버 메시지 봉투 {type, version, payload, seq, ts} - 모르는 type은 무시
+const WS_PROTOCOL_VERSION = 1;
+let lastMessageSeq = 0;
+
+const messageHandlers = {
+  subtitle: payload => {
+    console.log('Received subtitle data:', payload);
+    updateSubtitleOverlay(payload);
+  },
+  status: payload => {
+    if (typeof window.applySystemStatus === 'function') {
+      window.applySystemStatus(payload, true);
+    }
+  },
+  stream: payload => {
+    console.log('Stream event:', payload);
+  },
+  session: payload => {
+    console.log('Transcript session event:', payload);
+  },
+  subscriptions: payload => {
+    console.log('WebSocket subscriptions:', payload);
+  },
+  notice: payload => {
+    // 전송이 밀려 서버가 메시지를 버렸거나 연결을 끊음 (끊기면 onclose에서 재연결)
+    console.warn('WebSocket notice:', payload);
+  }
+};
+
+function handleServerMessage(message) {
+  if (message.version > WS_PROTOCOL_VERSION) {
+    console.warn(`Unsupported message version ${message.version} (type: ${message.type})`);
+    return;
+  }
+  if (message.seq <= lastMessageSeq) {
+    // 서버 재시작 - 순번이 처음부터 다시 시작됨
+    console.log('Message sequence restarted');
+  }
+  lastMessageSeq = message.seq;
+
+  const handler = messageHandlers[message.type];
+  if (handler) {
+    handler(message.payload);
+  }
+}
+
 function updateSubtitleOverlay(subtitleData) {
   const subtitleBox = document.getElementById('subtitleBox');
   const emoji = document.getElementById('subtitleEmoji');
@@ -77,7 +198,7 @@ function updateSubtitleOverlay(subtitleData) {
   // 자막 데이터 업데이트
   emoji.textContent = subtitleData.emoji || '🙂';
   langCode.textContent = subtitleData.lang_code || 'KR';
-  timestamp.textContent = subtitleData.timestamp || '00:00:00';
+  timestamp.textContent = formatSubtitleTimestamp(subtitleData.timestamp);
   text.textContent = subtitleData.text;
   speaker.textContent = subtitleData.speaker == -1 ? 'Detecting...' : `Speaker ${subtitleData.speaker}`;
 
@@ -106,6 +227,13 @@ function updateSubtitleOverlay(subtitleData) {
   }
 }
 
+// 초 단위 타임스탬프를 hh:mm:ss로 표시
+function formatSubtitleTimestamp(seconds) {
+  const total = Math.max(0, Math.floor(Number(seconds) || 0));
+  const pad = n => String(n).padStart(2, '0');
+  return `${pad(Math.floor(total / 3600))}:${pad(Math.floor(total / 60) % 60)}:${pad(total % 60)}`;
+}
+
 function hideSubtitleOverlay() {
   const subtitleBox = document.getElementById('subtitleBox');
   if (subtitleBox) {
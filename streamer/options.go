@@ -0,0 +1,113 @@
+package streamer
+
+import (
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/session"
+	"webrtc-streamer/internal/subtitles"
+)
+
+// ---------- Options ----------
+
+// 자막 한 건 (POST /subtitle 본문과 같은 형식)
+type Subtitle = subtitles.Data
+
+// DefaultOptions()에서 시작해 필요한 값만 바꿔 New에 넘긴다.
+// 기간/크기 값이 0이면 해당 기능을 끈다는 뜻인 항목은 주석에 적어 두었다.
+type Options struct {
+	// HTTP
+	HTTPAddr string // Start가 직접 열 주소 (""이면 Mount한 mux에서만 제공)
+	Static   fs.FS  // "/"에서 제공할 웹 프론트엔드 (nil이면 제공하지 않음)
+
+	// RTP 수신 (GStreamer)
+	RTPBindIP    string
+	RTPVideoPort int
+	RTPAudioPort int
+
+	// WebRTC
+	ICEServers    []webrtc.ICEServer // localhost/내부망 접속에는 쓰지 않는다
+	WebRTCBindIP  string
+	WebRTCUDPPort int      // 0이면 세션마다 임시 포트
+	WebRTCTCPPort int      // 0이면 ICE-TCP를 쓰지 않음
+	PublicIPs     []string // NAT 1:1 외부 주소
+
+	// 이벤트 허브 (WebSocket/SSE)
+	PartialInterval    time.Duration // 부분 자막 최소 전송 간격
+	PublishQueue       int
+	ClientQueue        int
+	History            int           // 재접속 시 다시 보낼 최근 메시지 수
+	Compression        bool          // WebSocket permessage-deflate 협상
+	StatusPushInterval time.Duration // 0이면 상태를 주기적으로 보내지 않음
+
+	// 자막
+	MaxSubtitleTextLength int
+	TranscriptMaxSessions int
+	LiveVTTSegment        time.Duration
+	LiveVTTWindow         int
+	TranslateURL          string // ""이면 번역하지 않음
+	TranslateAPIKey       string
+	TranslateTimeout      time.Duration
+
+	// 시간 이동 버퍼
+	DVRWindow   time.Duration // 0이면 끔
+	DVRMaxBytes int
+
+	// 녹화
+	RecordingDir       string
+	Continuous         bool // 연속 녹화
+	RecordSegment      time.Duration
+	RecordRetention    time.Duration // 0이면 기간 제한 없음
+	RecordMinFreeBytes uint64
+
+	// HLS, 스냅샷
+	HLSSegment          time.Duration
+	HLSWindow           int
+	SnapshotJPEGCommand string // ""이면 JPEG 변환 없이 키프레임만 제공
+
+	// 훅 (모두 nil 가능)
+	Status     func() any                  // /status와 주기적 상태 전송 (nil이면 시스템 상태)
+	OnSubtitle func(Subtitle)              // 발행된 자막마다 호출
+	Authorize  func(r *http.Request) error // 정적 파일을 제외한 요청을 검사, 오류면 401
+	// /ws, /events 접속 요청이 운영용 토픽(admin, presence)을 구독할 수 있는지, GET /presence를 검사 (nil이면 모두 허용)
+	AuthorizeAdmin func(r *http.Request) error
+}
+
+// 환경 변수가 없을 때 서버가 쓰는 값과 같다
+func DefaultOptions() Options {
+	return Options{
+		HTTPAddr: ":8080",
+
+		RTPBindIP:    "0.0.0.0",
+		RTPVideoPort: 5004,
+		RTPAudioPort: 5006,
+
+		ICEServers: session.DefaultICEServers,
+
+		PartialInterval:    250 * time.Millisecond,
+		PublishQueue:       1024,
+		ClientQueue:        256,
+		History:            500,
+		Compression:        true,
+		StatusPushInterval: 10 * time.Second,
+
+		MaxSubtitleTextLength: 500,
+		TranscriptMaxSessions: 20,
+		LiveVTTSegment:        6 * time.Second,
+		LiveVTTWindow:         10,
+		TranslateTimeout:      3 * time.Second,
+
+		DVRMaxBytes: 100 << 20, // DVRWindow를 지정해야 켜진다
+
+		RecordingDir:       "./recordings",
+		RecordSegment:      5 * time.Minute,
+		RecordRetention:    72 * time.Hour,
+		RecordMinFreeBytes: 1 << 30,
+
+		HLSSegment: 2 * time.Second,
+		HLSWindow:  6,
+	}
+}
@@ -0,0 +1,262 @@
+package streamer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"webrtc-streamer/internal/dvr"
+	"webrtc-streamer/internal/hls"
+	"webrtc-streamer/internal/httpapi"
+	"webrtc-streamer/internal/hub"
+	"webrtc-streamer/internal/ingest"
+	"webrtc-streamer/internal/recording"
+	"webrtc-streamer/internal/session"
+	"webrtc-streamer/internal/snapshot"
+	"webrtc-streamer/internal/subtitles"
+	"webrtc-streamer/internal/sysstatus"
+)
+
+// ---------- Server ----------
+
+// RTP 수신부터 WebRTC/HLS 송출, 자막, 녹화까지 묶은 스트리밍 서버.
+// 다른 Go 프로그램에 넣을 때는 New → Mount(mux) → Start → Stop 순서로 쓴다.
+type Server struct {
+	opts Options
+
+	api        *httpapi.API
+	hub        *hub.Hub
+	sessions   *session.Manager
+	recordings *recording.Manager
+	video      *ingest.RTPSource
+	audio      *ingest.RTPSource
+	network    *session.Network
+
+	mu         sync.Mutex
+	started    bool
+	httpServer *http.Server
+	stopStatus context.CancelFunc
+}
+
+// 구성 요소를 만들고 RTP/WebRTC 포트를 연다. 이벤트 허브도 여기서 돌기 시작하므로
+// Start 전에 Mount한 /ws, /events, /ws/stats도 응답한다. 나머지 서비스는 Start 이후에 시작된다.
+func New(opts Options) (*Server, error) {
+	s := &Server{opts: opts}
+
+	status := opts.Status
+	if status == nil {
+		status = func() any { return sysstatus.Get() }
+	}
+	s.opts.Status = status
+
+	// WebSocket Hub 초기화
+	s.hub = hub.New(hub.Config{
+		PartialInterval: opts.PartialInterval,
+		QueueSize:       opts.PublishQueue,
+		ClientQueueSize: opts.ClientQueue,
+		HistorySize:     opts.History,
+		// permessage-deflate - 브라우저가 지원하면 협상한다
+		Compression: opts.Compression,
+		Authorize:   opts.AuthorizeAdmin,
+	})
+	go s.hub.Run()
+
+	utterances := subtitles.NewUtteranceTracker()
+
+	// 시청자가 요청한 언어로 최종 자막 번역 (TranslateURL이 없으면 끔)
+	var translations *subtitles.Translations
+	if opts.TranslateURL != "" {
+		translator := subtitles.NewHTTPTranslator(opts.TranslateURL, opts.TranslateAPIKey, opts.TranslateTimeout)
+		translations = subtitles.NewTranslations(translator, s.hub, opts.TranslateTimeout, opts.MaxSubtitleTextLength)
+	}
+
+	// 세션별 자막 기록 저장소
+	transcripts := subtitles.NewTranscriptStore(opts.TranscriptMaxSessions,
+		func(e subtitles.SessionEvent) { s.hub.Publish(hub.TypeSession, e) })
+
+	// HLS/DASH 플레이어용 라이브 WebVTT 자막
+	liveSubtitles := subtitles.NewLiveTrack(opts.LiveVTTSegment, opts.LiveVTTWindow)
+
+	network, err := session.NewNetwork(session.NetworkConfig{
+		BindIP:    opts.WebRTCBindIP,
+		UDPPort:   opts.WebRTCUDPPort,
+		TCPPort:   opts.WebRTCTCPPort,
+		PublicIPs: opts.PublicIPs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WebRTC network: %w", err)
+	}
+	s.network = network
+
+	// RTP 수신은 시청자 유무와 관계없이 유지 (실패 시 세션 요청 때 다시 시도)
+	s.video = ingest.NewRTPSource("Video", opts.RTPBindIP, opts.RTPVideoPort)
+	s.audio = ingest.NewRTPSource("Audio", opts.RTPBindIP, opts.RTPAudioPort)
+	for _, src := range []*ingest.RTPSource{s.video, s.audio} {
+		if err := src.Start(); err != nil {
+			log.Printf("RTP ingest not started: %v", err)
+		}
+	}
+
+	// 라이브 되감기용 시간 이동 버퍼
+	var timeShift *dvr.Buffer
+	if opts.DVRWindow > 0 {
+		timeShift = dvr.NewBuffer(opts.DVRWindow, opts.DVRMaxBytes, s.video, s.audio)
+	}
+
+	// 클립은 시간 이동 버퍼가 꺼져 있으면 녹화 파일에서만 자른다
+	var clipSource recording.TimeShift
+	if timeShift != nil {
+		clipSource = timeShift
+	}
+	s.recordings = recording.NewManager(recording.Config{
+		Dir:          opts.RecordingDir,
+		MinFreeBytes: opts.RecordMinFreeBytes,
+		Video:        s.video,
+		Audio:        s.audio,
+		Transcripts:  transcripts,
+		TimeShift:    clipSource,
+	})
+
+	// 대시보드용 스냅샷 (마지막 키프레임)
+	keyframes := snapshot.NewKeyframeCache(s.video, opts.SnapshotJPEGCommand)
+
+	s.sessions = session.NewManager(session.Config{
+		Network:    network,
+		ICEServers: opts.ICEServers,
+		Video:      s.video,
+		Audio:      s.audio,
+		DVR:        timeShift,
+	})
+
+	// WebRTC(UDP)를 쓸 수 없는 시청자용 HLS
+	hlsOutput := hls.NewOutput(liveSubtitles.Epoch(), opts.HLSSegment, opts.HLSWindow, s.video, s.audio)
+
+	s.api = httpapi.New(httpapi.Config{
+		Hub:                   s.hub,
+		Sessions:              s.sessions,
+		Transcripts:           transcripts,
+		LiveSubtitles:         liveSubtitles,
+		Utterances:            utterances,
+		Translations:          translations,
+		Recordings:            s.recordings,
+		HLS:                   hlsOutput,
+		Keyframes:             keyframes,
+		DVR:                   timeShift,
+		Status:                status,
+		OnSubtitle:            opts.OnSubtitle,
+		Authorize:             opts.Authorize,
+		MaxSubtitleTextLength: opts.MaxSubtitleTextLength,
+		Compression:           opts.Compression,
+	})
+	return s, nil
+}
+
+// 엔드포인트를 mux에 등록한다. Static이 있으면 "/"도 등록하므로
+// 다른 서비스와 mux를 함께 쓸 때는 경로가 겹치지 않게 한다.
+func (s *Server) Mount(mux *http.ServeMux) {
+	if s.opts.Static != nil {
+		// 정적 파일 서버에 캐시 방지 미들웨어 추가
+		mux.Handle("/", noCacheMiddleware(http.FileServerFS(s.opts.Static)))
+	}
+	s.api.Register(mux)
+}
+
+// 주기적 상태 전송과 연속 녹화를 시작하고
+// HTTPAddr가 있으면 자체 mux로 HTTP 서버를 연다
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("server already started")
+	}
+
+	if s.opts.HTTPAddr != "" {
+		ln, err := net.Listen("tcp", s.opts.HTTPAddr)
+		if err != nil {
+			return fmt.Errorf("HTTP listen %s: %w", s.opts.HTTPAddr, err)
+		}
+		mux := http.NewServeMux()
+		s.Mount(mux)
+		s.httpServer = &http.Server{Handler: mux}
+		go func() {
+			if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Printf("HTTP Server error: %v", err)
+			}
+		}()
+		log.Printf("📍 Server: http://%s", ln.Addr())
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopStatus = cancel
+	if s.opts.StatusPushInterval > 0 {
+		go s.hub.StatusLoop(ctx, s.opts.StatusPushInterval, s.opts.Status)
+	}
+
+	if s.opts.Continuous {
+		err := s.recordings.StartContinuous(recording.ContinuousConfig{
+			SegmentLength: s.opts.RecordSegment,
+			MaxAge:        s.opts.RecordRetention,
+			MinFreeBytes:  s.opts.RecordMinFreeBytes,
+		})
+		if err != nil {
+			log.Printf("Continuous recording not started: %v", err)
+		}
+	}
+	return nil
+}
+
+// 실행 중에 바꿀 수 있는 설정 (SIGHUP 설정 다시 읽기 등)
+type Settings struct {
+	ICEServers            []webrtc.ICEServer // 이후 세션부터 적용
+	PartialInterval       time.Duration
+	MaxSubtitleTextLength int
+}
+
+func (s *Server) UpdateSettings(settings Settings) {
+	s.sessions.SetICEServers(settings.ICEServers)
+	s.hub.SetPartialInterval(settings.PartialInterval)
+	s.api.SetMaxSubtitleTextLength(settings.MaxSubtitleTextLength)
+
+	s.mu.Lock()
+	s.opts.ICEServers = settings.ICEServers
+	s.opts.PartialInterval = settings.PartialInterval
+	s.opts.MaxSubtitleTextLength = settings.MaxSubtitleTextLength
+	s.mu.Unlock()
+}
+
+// HTTP를 거치지 않고 자막을 발행한다 (POST /subtitle과 같은 검증을 거친다)
+func (s *Server) PublishSubtitle(subtitle Subtitle) error {
+	return s.api.PublishSubtitle(subtitle)
+}
+
+// 캐시 방지 미들웨어 - 개발 중에는 캐시 비활성화
+func noCacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// HTML, CSS, JS 파일은 캐시 방지
+		if isDevResource(r.URL.Path) {
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			w.Header().Set("Pragma", "no-cache")
+			w.Header().Set("Expires", "0")
+		} else {
+			// 이미지 등 정적 리소스는 짧은 캐시 허용
+			w.Header().Set("Cache-Control", "public, max-age=300") // 5분
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 개발 리소스 확인 (HTML, CSS, JS)
+func isDevResource(path string) bool {
+	return path == "/" ||
+		path == "/index.html" ||
+		len(path) >= 4 && (path[len(path)-4:] == ".css" ||
+			path[len(path)-3:] == ".js" ||
+			path[len(path)-5:] == ".html")
+}
@@ -0,0 +1,82 @@
+package streamer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 포트를 모두 임시 포트로 바꾸고 녹화/상태 전송을 끈 옵션
+func testOptions(t *testing.T) Options {
+	opts := DefaultOptions()
+	opts.HTTPAddr = ""
+	opts.RTPBindIP = "127.0.0.1"
+	opts.RTPVideoPort, opts.RTPAudioPort = 0, 0
+	opts.WebRTCBindIP = "127.0.0.1"
+	opts.ICEServers = nil
+	opts.StatusPushInterval = 0
+	opts.RecordingDir = t.TempDir()
+	opts.RecordMinFreeBytes = 0
+	return opts
+}
+
+// Start 전에 Mount한 mux도 /ws/stats와 /events에 응답해야 한다
+func TestHandlersRespondBeforeStart(t *testing.T) {
+	s, err := New(testOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.Mount(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// SSE 접속은 hub에 등록되고 첫 줄을 받는다
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	events, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer events.Body.Close()
+	if line, err := bufio.NewReader(events.Body).ReadString('\n'); err != nil || !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("/events first line = %q, %v", line, err)
+	}
+
+	var stats struct {
+		Clients int `json:"clients"`
+	}
+	for i := 0; i < 50; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/ws/stats", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("/ws/stats before Start: %v", err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&stats)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Clients == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats.Clients != 1 {
+		t.Errorf("/ws/stats clients = %d, want the SSE client", stats.Clients)
+	}
+
+	// Start 없이 Stop해도 hub 클라이언트를 닫고 끝난다
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	if err := s.Stop(stopCtx); err != nil {
+		t.Errorf("Stop() = %v", err)
+	}
+}
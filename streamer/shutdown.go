@@ -0,0 +1,68 @@
+package streamer
+
+import (
+	"context"
+	"log"
+)
+
+// ---------- Graceful shutdown ----------
+
+const shutdownReason = "server shutting down"
+
+// 종료 순서:
+// 새 세션 거부 → hub 클라이언트에 닫기 프레임 → PeerConnection 종료 → 녹화 마무리 → RTP/ICE 포트 닫기.
+// ctx가 끝날 때까지 마치지 못하면 남은 HTTP 연결을 끊고 ctx의 오류를 반환한다.
+// Mount만 한 경우 HTTP 서버 종료는 호출한 쪽이 맡는다.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.httpServer
+	stopStatus := s.stopStatus
+	s.mu.Unlock()
+
+	log.Printf("Shutting down")
+	s.api.BeginShutdown()
+	if stopStatus != nil {
+		stopStatus()
+	}
+
+	// 리스너는 바로 닫히고, 진행 중인 HTTP 요청은 아래 작업과 함께 기다린다
+	httpDone := make(chan error, 1)
+	if srv != nil {
+		go func() { httpDone <- srv.Shutdown(ctx) }()
+	} else {
+		httpDone <- nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		if err := s.hub.Shutdown(ctx, shutdownReason); err != nil {
+			log.Printf("Shutdown: hub: %v", err)
+		}
+		if err := s.sessions.Close(ctx); err != nil {
+			log.Printf("Shutdown: WebRTC sessions: %v", err)
+		}
+		if err := s.recordings.Close(); err != nil {
+			log.Printf("Shutdown: recording: %v", err)
+		}
+		s.video.Close()
+		s.audio.Close()
+		s.network.Close()
+
+		if err := <-httpDone; err != nil {
+			log.Printf("Shutdown: HTTP: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+		log.Printf("Shutdown complete")
+		return nil
+	case <-ctx.Done():
+		if srv != nil {
+			srv.Close()
+		}
+		return ctx.Err()
+	}
+}